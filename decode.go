@@ -14,6 +14,14 @@ type Unmarshaler interface {
 	UnmarshalENV() error
 }
 
+// UnmarshalerFrom is the interface implemented by types that can unmarshal
+// themselves from the source of variables. It takes precedence over the
+// Unmarshaler interface: the UnmarshalFrom passes its source, the other
+// functions pass the environment of the process.
+type UnmarshalerFrom interface {
+	UnmarshalENVFrom(src Lookuper) error
+}
+
 // unmarshalENV gets variables from the environment and sets them
 // into object by pointer. Returns an error if something went wrong.
 //
@@ -28,7 +36,41 @@ type Unmarshaler interface {
 // Among the supported types are: struct and pointer to struct but
 // slice/array of these types is not supported (except url.URL and
 // *url.URL from the net package).
-func unmarshalENV(obj interface{}, pfx string) error {
+//
+// The keys of the tags are validated according to the options, the
+// values are taken from the source of the options. The fields with the
+// required option of the tag are checked all together: the MissingError
+// lists all missing variables, and the ValidationErrors lists all values
+// that violate the constraints of the tags.
+func unmarshalENV(obj interface{}, pfx string, o *options) error {
+	d := &decoder{options: o}
+	if err := d.decode(obj, pfx, ""); err != nil {
+		return err
+	}
+
+	switch {
+	case len(d.missing) != 0 && len(d.invalid) != 0:
+		return errors.Join(&MissingError{Fields: d.missing}, d.invalid)
+	case len(d.missing) != 0:
+		return &MissingError{Fields: d.missing}
+	case len(d.invalid) != 0:
+		return d.invalid
+	}
+
+	return nil
+}
+
+// decoder is the state of the unmarshalling of the structure.
+type decoder struct {
+	*options
+	missing []MissingField   // required fields without values
+	invalid ValidationErrors // values that violate the constraints
+}
+
+// decode sets variables into object by pointer, the pfx is the prefix
+// of the keys and the path is the path of the object's fields, like
+// Config.DB (the name of the object's type is used for the empty path).
+func (d *decoder) decode(obj interface{}, pfx, path string) error {
 	inst := instance{}
 	inst.Init(obj)
 
@@ -42,9 +84,16 @@ func unmarshalENV(obj interface{}, pfx string) error {
 		return errors.New("object isn't a struct")
 	}
 
-	// If objects implements Unmarshaler interface try to calling
-	// a custom Unmarshal method.
-	if inst.Implements((*Unmarshaler)(nil)) {
+	// If objects implements UnmarshalerFrom or Unmarshaler interface
+	// try to calling a custom Unmarshal method.
+	if inst.Implements((*UnmarshalerFrom)(nil)) {
+		m := inst.Ptr.MethodByName("UnmarshalENVFrom")
+		tmp := m.Call([]reflect.Value{reflect.ValueOf(d.source())})
+		if err := tmp[0].Interface(); err != nil {
+			return fmt.Errorf("env: unmarshal: %v", err)
+		}
+		return nil
+	} else if inst.Implements((*Unmarshaler)(nil)) {
 		if m := inst.Ptr.MethodByName("UnmarshalENV"); m.IsValid() {
 			tmp := m.Call([]reflect.Value{})
 			err := tmp[0].Interface()
@@ -55,6 +104,10 @@ func unmarshalENV(obj interface{}, pfx string) error {
 		}
 	}
 
+	if len(path) == 0 {
+		path = inst.Type.Name()
+	}
+
 	// Walk through all the fields of the struct.
 	for i := 0; i < inst.Value.NumField(); i++ {
 		// Get item.
@@ -62,21 +115,31 @@ func unmarshalENV(obj interface{}, pfx string) error {
 		item := inst.Value.FieldByName(field.Name)
 
 		// Get key and sep for sequences.
-		key, value, sep, err := splitFieldTag(field.Tag.Get("env"))
+		tag, err := parseFieldTag(field.Tag.Get("env"), d.validKey)
 		if err != nil {
 			return err
 		}
+		key, value, sep := tag.key, tag.value, tag.sep
 
-		// Create full key name.
+		// Create full key name and path of the field.
 		if len(key) == 0 {
 			key = field.Name
 		}
 
 		key = fmt.Sprintf("%s%s", pfx, key)
+		name := field.Name
+		if len(path) != 0 {
+			name = fmt.Sprintf("%s.%s", path, field.Name)
+		}
 
 		// If the value is defined in environment set it into value.
-		if Exists(key) {
-			value = Get(key)
+		tmp, ok := d.lookupEnv(key)
+		switch {
+		case ok:
+			value = tmp
+		case tag.required && len(value) == 0 && !isNested(item.Type()):
+			d.missing = append(d.missing, MissingField{Field: name, Key: key})
+			continue
 		}
 
 		// Set values of the desired type.
@@ -119,7 +182,7 @@ func unmarshalENV(obj interface{}, pfx string) error {
 				// If a pointer to a structure of the another's types.
 				// P.s. Not a *url.URL.
 				tmp := reflect.New(item.Type().Elem()).Interface()
-				err := unmarshalENV(tmp, fmt.Sprintf("%s_", key))
+				err := d.decode(tmp, fmt.Sprintf("%s_", key), name)
 				if err != nil {
 					return err
 				}
@@ -137,7 +200,7 @@ func unmarshalENV(obj interface{}, pfx string) error {
 				// If a structure of the another's types.
 				// P.s. Not a url.URL.
 				tmp := reflect.New(item.Type()).Interface()
-				err := unmarshalENV(tmp, fmt.Sprintf("%s_", key))
+				err := d.decode(tmp, fmt.Sprintf("%s_", key), name)
 				if err != nil {
 					return err
 				}
@@ -150,11 +213,61 @@ func unmarshalENV(obj interface{}, pfx string) error {
 				return err
 			}
 		}
+
+		// Check the constraints of the value.
+		if err := d.validate(tag, item, name, key, value); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// validate checks the value of the field by the constraints of the tag,
+// the violations are added into the invalid list. The constraints except
+// nonempty are skipped for the empty value. Returns an error if the
+// constraint cannot be applied to the type of the field.
+func (d *decoder) validate(tag fieldTag, item reflect.Value,
+	name, key, value string) error {
+	seq := []string{value}
+	switch {
+	case len(value) == 0:
+		seq = nil
+	case item.Kind() == reflect.Array || item.Kind() == reflect.Slice:
+		seq = strings.Split(value, tag.sep)
+	}
+
+	for _, r := range tag.rules {
+		if len(value) == 0 && r.name != "nonempty" {
+			continue
+		}
+
+		ok, err := r.check(item, value, seq)
+		if err != nil {
+			return fmt.Errorf("%s (%s): %v", name, key, err)
+		} else if !ok {
+			d.invalid = append(d.invalid, &ValidationError{
+				Field:      name,
+				Key:        key,
+				Value:      value,
+				Constraint: r.String(),
+			})
+		}
+	}
+
+	return nil
+}
+
+// isNested returns true if the field of the t type is processed as
+// the nested structure (the url.URL and *url.URL are values).
+func isNested(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct && t != reflect.TypeOf(url.URL{})
+}
+
 // setSequence sets slice into item.
 func setSequence(item *reflect.Value, seq []string) (err error) {
 	var kind = item.Index(0).Kind()
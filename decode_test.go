@@ -1,6 +1,7 @@
 package env
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"reflect"
@@ -55,7 +56,7 @@ func (c *dataUnmarshalENV) UnmarshalENV() error {
 // of an exception for a non-pointer value.
 func TestUnmarshalENVNotPointer(t *testing.T) {
 	type data struct{}
-	if err := unmarshalENV(data{}, ""); err == nil {
+	if err := unmarshalENV(data{}, "", &options{}); err == nil {
 		t.Error("An error is expected for non-pointer value.")
 	}
 }
@@ -65,7 +66,7 @@ func TestUnmarshalENVNotPointer(t *testing.T) {
 func TestUnmarshalENVNotInitialized(t *testing.T) {
 	type data struct{}
 	var d *data
-	if err := unmarshalENV(d, ""); err == nil {
+	if err := unmarshalENV(d, "", &options{}); err == nil {
 		t.Error("An error is expected for not initialized value.")
 	}
 }
@@ -74,7 +75,7 @@ func TestUnmarshalENVNotInitialized(t *testing.T) {
 // of an exception for a value that isn't struct.
 func TestUnmarshalENVNotStruct(t *testing.T) {
 	var d = new(int)
-	if err := unmarshalENV(d, ""); err == nil {
+	if err := unmarshalENV(d, "", &options{}); err == nil {
 		t.Error("An error is expected for a pointer not to a structure.")
 	}
 }
@@ -128,7 +129,7 @@ func TestUnmarshalENVNumbers(t *testing.T) {
 			}
 
 			// Unmarshaling.
-			err = unmarshalENV(d, "")
+			err = unmarshalENV(d, "", &options{})
 
 			// Check error of the unmarshalling.
 			switch i {
@@ -209,7 +210,7 @@ func TestUnmarshalENVBool(t *testing.T) {
 			t.Error(err)
 		}
 
-		err = unmarshalENV(d, "")
+		err = unmarshalENV(d, "", &options{})
 		if err != nil {
 			t.Error(err)
 		}
@@ -232,7 +233,7 @@ func TestUnmarshalENVBool(t *testing.T) {
 			t.Error(err)
 		}
 
-		err = unmarshalENV(d, "")
+		err = unmarshalENV(d, "", &options{})
 		if err == nil {
 			t.Error("didn't handle the error")
 		}
@@ -267,7 +268,7 @@ func TestUnmarshalENVString(t *testing.T) {
 			t.Error(err)
 		}
 
-		err = unmarshalENV(d, "")
+		err = unmarshalENV(d, "", &options{})
 		if err != nil {
 			t.Error(err)
 		}
@@ -352,7 +353,7 @@ func TestUnmarshalENVSlice(t *testing.T) {
 			t.Error(err)
 		}
 
-		err = unmarshalENV(d, "")
+		err = unmarshalENV(d, "", &options{})
 		if err != nil {
 			t.Error(err)
 		}
@@ -379,7 +380,7 @@ func TestUnmarshalENVSlice(t *testing.T) {
 			t.Error(err)
 		}
 
-		err = unmarshalENV(d, "")
+		err = unmarshalENV(d, "", &options{})
 		if err == nil {
 			t.Error("must be error for", value)
 		}
@@ -432,7 +433,7 @@ func TestUnmarshalENVArray(t *testing.T) {
 			t.Error(err)
 		}
 
-		err = unmarshalENV(d, "")
+		err = unmarshalENV(d, "", &options{})
 		if err != nil {
 			t.Error(err)
 		}
@@ -459,7 +460,7 @@ func TestUnmarshalENVArray(t *testing.T) {
 			t.Error(err)
 		}
 
-		err = unmarshalENV(d, "")
+		err = unmarshalENV(d, "", &options{})
 		if err == nil {
 			t.Error("There should be an exception due to an invalid value.")
 		}
@@ -478,7 +479,7 @@ func TestUnmarshalENVArray(t *testing.T) {
 			t.Error(err)
 		}
 
-		err = unmarshalENV(d, "")
+		err = unmarshalENV(d, "", &options{})
 		if err == nil {
 			t.Error("There should be an exception due to array overflow.")
 		}
@@ -539,7 +540,7 @@ func TestUnmarshalURL(t *testing.T) {
 	}
 
 	// Unmarshaling.
-	err = unmarshalENV(&d, "")
+	err = unmarshalENV(&d, "", &options{})
 	if err != nil {
 		t.Error(err)
 	}
@@ -630,7 +631,7 @@ func TestUnmarshalStruct(t *testing.T) {
 	}
 
 	// Unmarshaling.
-	err = unmarshalENV(&c, "")
+	err = unmarshalENV(&c, "", &options{})
 	if err != nil {
 		t.Error("Incorrect ummarshaling.")
 	}
@@ -683,7 +684,7 @@ func TestUnmarshalStructPtr(t *testing.T) {
 	}
 
 	// Unmarshaling.
-	err = unmarshalENV(&c, "")
+	err = unmarshalENV(&c, "", &options{})
 	if err != nil {
 		t.Error("Incorrect ummarshaling.")
 	}
@@ -724,7 +725,7 @@ func TestUnmarshalENVCustom(t *testing.T) {
 		}
 	}
 
-	err = unmarshalENV(c, "")
+	err = unmarshalENV(c, "", &options{})
 	if err != nil {
 		t.Error(err)
 	}
@@ -761,7 +762,7 @@ func TestUnmarshalENVStringPtr(t *testing.T) {
 		t.Error(err)
 	}
 
-	err = unmarshalENV(&d, "")
+	err = unmarshalENV(&d, "", &options{})
 	if err != nil {
 		t.Error(err)
 	}
@@ -794,7 +795,7 @@ func TestUnmarshalDefaultValue(t *testing.T) {
 
 	// Unmarshaling wit default values.
 	d = data{}
-	err = unmarshalENV(&d, "")
+	err = unmarshalENV(&d, "", &options{})
 	if err != nil {
 		t.Error("Incorrect ummarshaling.")
 	}
@@ -821,7 +822,7 @@ func TestUnmarshalDefaultValue(t *testing.T) {
 
 	// Unmarshaling wit environment values.
 	d = data{}
-	err = unmarshalENV(&d, "")
+	err = unmarshalENV(&d, "", &options{})
 	if err != nil {
 		t.Error("Incorrect ummarshaling.")
 	}
@@ -838,3 +839,164 @@ func TestUnmarshalDefaultValue(t *testing.T) {
 		t.Errorf("Names setas as default %s", d.Names)
 	}
 }
+
+// requiredConfig is the structure with required fields.
+type requiredConfig struct {
+	Host  string   `env:"HOST,,,required"`
+	Port  int      `env:"PORT,8080,,required"`      // has default value
+	Hosts []string `env:"ALLOWED_HOSTS,,,required"` // default separator
+	Debug bool     `env:"DEBUG"`
+	DB    struct {
+		Host string `env:"HOST,,,required"`
+		Name string `env:"NAME,,,required"`
+	} `env:"DB"`
+}
+
+// TestUnmarshalRequired tests unmarshalENV for required fields.
+func TestUnmarshalRequired(t *testing.T) {
+	var (
+		c    requiredConfig
+		vars = map[string]string{"DB_NAME": "app", "ALLOWED_HOSTS": ""}
+		src  = Map(vars)
+	)
+
+	err := unmarshalENV(&c, "", &options{src: src})
+	me, ok := err.(*MissingError)
+	if !ok {
+		t.Fatalf("Expected MissingError but returns %v.", err)
+	}
+
+	expected := []MissingField{
+		{Field: "requiredConfig.Host", Key: "HOST"},
+		{Field: "requiredConfig.DB.Host", Key: "DB_HOST"},
+	}
+	if !reflect.DeepEqual(me.Fields, expected) {
+		t.Errorf("Expected %v but returns %v.", expected, me.Fields)
+	}
+
+	msg := "missing required variables: requiredConfig.Host (HOST), " +
+		"requiredConfig.DB.Host (DB_HOST)"
+	if err.Error() != msg {
+		t.Errorf("Incorrect message: %s", err)
+	}
+
+	// All required variables are set.
+	vars["HOST"], vars["DB_HOST"], vars["ALLOWED_HOSTS"] = "0.0.0.0", "db", "a:b"
+	c = requiredConfig{}
+	if err := unmarshalENV(&c, "", &options{src: src}); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Host != "0.0.0.0" || c.Port != 8080 || c.DB.Host != "db" ||
+		!reflect.DeepEqual(c.Hosts, []string{"a", "b"}) {
+		t.Errorf("Incorrect values: %v.", c)
+	}
+}
+
+// validationConfig is the structure with constraints.
+type validationConfig struct {
+	Port     int      `env:"PORT,8080,,min=1,max=65535"`
+	Level    string   `env:"LOG_LEVEL,info,,oneof=debug|info|warn"`
+	Name     string   `env:"NAME,,,nonempty,regex='^[a-z]{2,8}$'"`
+	Hosts    []string `env:"HOSTS,,;,hostport,len=2"`
+	Endpoint string   `env:"ENDPOINT,,,url"` // isn't checked if empty
+	Admin    struct {
+		Email string `env:"EMAIL,,,email"`
+	} `env:"ADMIN"`
+}
+
+// TestUnmarshalValidation tests unmarshalENV for constraints.
+func TestUnmarshalValidation(t *testing.T) {
+	var (
+		c    validationConfig
+		vars = map[string]string{
+			"PORT":        "0",
+			"LOG_LEVEL":   "trace",
+			"HOSTS":       "a:80",
+			"ADMIN_EMAIL": "admin",
+		}
+	)
+
+	err := unmarshalENV(&c, "", &options{src: Map(vars)})
+	ve, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("Expected ValidationErrors but returns %v.", err)
+	}
+
+	expected := []string{
+		`validationConfig.Port (PORT): value "0" violates min=1`,
+		`validationConfig.Level (LOG_LEVEL): value "trace" violates ` +
+			`oneof=debug|info|warn`,
+		`validationConfig.Name (NAME): value "" violates nonempty`,
+		`validationConfig.Hosts (HOSTS): value "a:80" violates len=2`,
+		`validationConfig.Admin.Email (ADMIN_EMAIL): value "admin" ` +
+			`violates email`,
+	}
+	if len(ve) != len(expected) {
+		t.Fatalf("Expected %d errors but returns %v.", len(expected), err)
+	}
+
+	for i, e := range ve {
+		if e.Error() != expected[i] {
+			t.Errorf("Expected %s but returns %s.", expected[i], e)
+		}
+	}
+
+	// Correct values.
+	vars = map[string]string{
+		"NAME":        "app",
+		"HOSTS":       "a:80;b:8080",
+		"ADMIN_EMAIL": "admin@example.com",
+	}
+	c = validationConfig{}
+	err = unmarshalENV(&c, "", &options{src: Map(vars)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Missing and invalid values are reported together.
+	type data struct {
+		Host string `env:"HOST,,,required"`
+		Port int    `env:"PORT,0,,min=1"`
+	}
+
+	err = unmarshalENV(&data{}, "", &options{src: Map(nil)})
+	var me *MissingError
+	if !errors.As(err, &me) || !errors.As(err, &ve) {
+		t.Errorf("Expected MissingError and ValidationErrors but "+
+			"returns %v.", err)
+	}
+
+	// Unsupported constraint.
+	type broken struct {
+		Debug bool `env:"DEBUG,true,,min=1"`
+	}
+
+	if err := unmarshalENV(&broken{}, "", &options{}); err == nil {
+		t.Error("Expected error for the unsupported constraint.")
+	}
+}
+
+// TestUnmarshalSeparatorComma tests the separator with the comma
+// that isn't followed by options.
+func TestUnmarshalSeparatorComma(t *testing.T) {
+	var c struct {
+		Items []string `env:"ITEMS,,a,b"`
+	}
+
+	vars := map[string]string{"ITEMS": "1a,b2a,b3"}
+	if err := UnmarshalFrom(Map(vars), &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(c.Items, []string{"1", "2", "3"}) {
+		t.Errorf("Expected [1 2 3] but returns %v.", c.Items)
+	}
+
+	vars = make(map[string]string)
+	if _, err := MarshalTo(Map(vars), &c); err != nil {
+		t.Fatal(err)
+	} else if vars["ITEMS"] != "1a,b2a,b3" {
+		t.Errorf("Expected `1a,b2a,b3` but returns `%s`.", vars["ITEMS"])
+	}
+}
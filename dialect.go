@@ -0,0 +1,234 @@
+package env
+
+import (
+	"os"
+	"strings"
+)
+
+// Format is the dialect of the env-file format, the tools that consume
+// env-files (docker, systemd, bash etc.) parse them differently.
+type Format int
+
+// Dialects of the env-file format.
+const (
+	// Native is the default format of the package (see ReadParseStore).
+	Native Format = iota
+
+	// Docker is the format of the `docker run --env-file` option: the
+	// value is taken literally (without quotes removing, comments and
+	// variable replacement), the line with a variable name only takes
+	// the value from the environment.
+	Docker
+
+	// Systemd is the format of the `EnvironmentFile=` directive of the
+	// systemd units: the value can be single- or double-quoted, the
+	// backslash escapes the next character and joins the lines, the `#`
+	// and `;` begin the comment lines only, variables are not replaced.
+	Systemd
+
+	// Bash is the format of the env-file sourced by bash: the value is
+	// a shell word made of unquoted, single-quoted, double-quoted and
+	// $'...' quoted parts, variables are replaced in the unquoted and
+	// double-quoted parts. The command substitution is not supported.
+	Bash
+
+	// Dotenv is the format of the dotenv package of Node.js: the keys
+	// can contain dots and dashes, the value can be quoted by single,
+	// double quotes or backticks, only \n and \r escapes are interpreted
+	// in the double-quoted value, variables are not replaced.
+	Dotenv
+)
+
+// String returns the name of the dialect.
+func (f Format) String() string {
+	switch f {
+	case Native:
+		return "native"
+	case Docker:
+		return "docker"
+	case Systemd:
+		return "systemd"
+	case Bash:
+		return "bash"
+	case Dotenv:
+		return "dotenv"
+	}
+
+	return "unknown"
+}
+
+// Dialect sets the dialect of the env-file format, the Native dialect
+// is used by default. The values of the Docker, Systemd and Dotenv
+// dialects are never replaced, as the consumers of these formats do.
+//
+// Example:
+//
+//    // Get the same variables as `docker run --env-file app.env`.
+//    err := env.Load("app.env", env.Dialect(env.Docker))
+//    if err != nil {
+//        // something went wrong
+//    }
+func Dialect(f Format) Option {
+	return func(o *options) {
+		o.dialect = f
+	}
+}
+
+// Behavior of the reader if the expression is still open (for example,
+// the quote is not closed) at the end of the data.
+const (
+	eofError = iota // unclosed quote error
+	eofJoin         // all remaining lines are the expression
+	eofSplit        // the first line only is the expression
+)
+
+// syntax implements the dialect of the env-file format.
+type syntax interface {
+	// empty returns true if exp is an empty line or comment.
+	empty(exp string) bool
+
+	// include returns path of the included env-file if exp
+	// is an include directive.
+	include(exp string) (path string, optional, ok bool)
+
+	// open returns the joiner of the lines if exp continues on
+	// the next line, or nil if exp is the whole expression.
+	open(exp string) joiner
+
+	// eof returns behavior of the reader for the open expression
+	// at the end of the data.
+	eof() int
+
+	// parse returns the entry of the exp expression, the entry with
+	// empty key means that the expression is ignored.
+	parse(exp string) (Entry, error)
+
+	// expands returns true if the variables in the values
+	// can be replaced.
+	expands() bool
+}
+
+// joiner keeps the state of the expression that takes several lines,
+// so only the next line is checked (not the whole expression).
+type joiner interface {
+	// add returns true if the expression continues after the line.
+	add(line string) bool
+}
+
+// syntax returns implementation of the dialect.
+func (f Format) syntax() syntax {
+	switch f {
+	case Docker:
+		return dockerSyntax{}
+	case Systemd:
+		return systemdSyntax{}
+	case Bash:
+		return bashSyntax{}
+	case Dotenv:
+		return dotenvSyntax{}
+	}
+
+	return nativeSyntax{}
+}
+
+// syntax returns implementation of the dialect of the options.
+func (o *options) syntax() syntax {
+	if o.dialect == Native {
+		return nativeSyntax{keys: o.keys}
+	}
+
+	return o.dialect.syntax()
+}
+
+// nativeSyntax implements the Native dialect.
+type nativeSyntax struct {
+	keys func(string) bool // validator of the keys, nil for POSIXKeys
+}
+
+func (nativeSyntax) empty(exp string) bool { return isEmpty(exp) }
+func (nativeSyntax) eof() int              { return eofError }
+func (nativeSyntax) expands() bool         { return true }
+
+func (nativeSyntax) open(exp string) joiner {
+	switch {
+	case isOpenQuote(exp):
+		if _, delimiter, ok := parseHeredoc(exp); ok {
+			return heredocJoiner(delimiter)
+		}
+
+		_, pos := lexAssign(exp)
+		return quoteJoiner(exp[pos])
+	case isContinued(exp):
+		return continuedJoiner{}
+	}
+
+	return nil
+}
+
+func (nativeSyntax) include(exp string) (string, bool, bool) {
+	return parseInclude(exp)
+}
+
+// heredocJoiner joins the lines of the heredoc block up to the line
+// equal to the delimiter.
+type heredocJoiner string
+
+func (d heredocJoiner) add(line string) bool { return line != string(d) }
+
+// quoteJoiner joins the lines of the quoted value up to the closing
+// quote, the escaped quotes are skipped in the double-quoted value.
+type quoteJoiner byte
+
+func (q quoteJoiner) add(line string) bool {
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && q == '"':
+			i++ // skip escaped character
+		case line[i] == byte(q):
+			return false
+		}
+	}
+
+	return true
+}
+
+// continuedJoiner joins the lines of the unquoted value while the line
+// ends with a backslash, the comment stops the joining.
+type continuedJoiner struct{}
+
+func (continuedJoiner) add(line string) bool {
+	n := len(line) - len(strings.TrimRight(line, "\\"))
+	return n%2 == 1 && strings.IndexByte(line, '#') < 0
+}
+
+func (n nativeSyntax) parse(exp string) (e Entry, err error) {
+	if key, action, ok := parseDirective(exp); ok {
+		valid := n.keys
+		if valid == nil {
+			valid = POSIXKeys
+		}
+
+		if !valid(key) {
+			offset := len(exp) - len(strings.TrimLeft(exp, " \t"))
+			return e, newParseError(IncorrectKey, exp, offset)
+		}
+
+		e.Key, e.Action = key, action
+		if action == Inherit {
+			e.Value, _ = os.LookupEnv(key)
+			e.raw = e.Value
+		}
+		return e, nil
+	}
+
+	if strings.Contains(exp, "\n") && isUnquoted(exp) {
+		exp = strings.ReplaceAll(exp, "\\\n", "") // line continuation
+	}
+
+	e.Key, e.raw, e.Quote, err = parseExpression(exp, n.keys)
+	if err == nil {
+		e.Value, err = unquote(e.raw, e.Quote, nil)
+	}
+
+	return e, err
+}
@@ -0,0 +1,463 @@
+package env
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// errIncomplete is returned if the shell word continues on the next line.
+var errIncomplete = errors.New("incomplete word")
+
+// bashSyntax implements the Bash dialect, the rules are the same as for
+// the assignments of the bash script:
+//
+//    - the expression is `[export] KEY=word [# comment]`, spaces around
+//      the `=` sign aren't allowed;
+//    - the word is made of unquoted, single-quoted, double-quoted and
+//      $'...' (ANSI-C) quoted parts, which can take several lines;
+//    - the backslash in the unquoted part escapes the next character,
+//      in the double-quoted part it escapes `$`, "`", `"`, `\` and the
+//      end of line only;
+//    - variables are replaced in the unquoted and double-quoted parts;
+//    - the `source file` and `. file` directives include the file;
+//    - the command substitution and special parameters like $1 or $?
+//      aren't supported.
+type bashSyntax struct{}
+
+func (bashSyntax) empty(exp string) bool { return isEmpty(exp) }
+func (bashSyntax) eof() int              { return eofError }
+func (bashSyntax) expands() bool         { return true }
+
+func (bashSyntax) include(exp string) (string, bool, bool) {
+	path, optional, ok := parseInclude(exp)
+	if !ok || optional || strings.HasPrefix(strings.TrimSpace(exp), "#") {
+		return "", false, false // #include is a comment for bash
+	}
+
+	return path, false, true
+}
+
+func (bashSyntax) open(exp string) joiner {
+	if _, err := bashParse(exp); err != errIncomplete {
+		return nil
+	}
+
+	// The word begins after the `=` sign of the assignment.
+	j := &bashJoiner{}
+	j.scan(exp[strings.IndexByte(exp, '=')+1:])
+
+	return j
+}
+
+func (bashSyntax) parse(exp string) (Entry, error) {
+	e, err := bashParse(exp)
+	if err == errIncomplete {
+		offset := strings.Index(exp, "=") + 1
+		return e, newParseError(UnclosedQuote, exp, offset)
+	}
+
+	return e, err
+}
+
+// bashParse parses the exp assignment by the rules of the bash.
+// Returns errIncomplete if the word continues on the next line.
+func bashParse(exp string) (e Entry, err error) {
+	// Skip the `export` prefix.
+	i, exported := len(exp)-len(strings.TrimLeft(exp, " \t")), false
+	if rest := strings.TrimPrefix(exp[i:], "export"); len(rest) < len(exp[i:]) {
+		if tmp := strings.TrimLeft(rest, " \t"); len(tmp) < len(rest) {
+			i, exported = len(exp)-len(tmp), true
+		}
+	}
+
+	// The variable name.
+	n := nameLength(exp[i:])
+	if !exported && exp[i:i+n] == "unset" &&
+		(i+n == len(exp) || exp[i+n] == ' ' || exp[i+n] == '\t') {
+		return bashUnset(exp, i+n)
+	}
+
+	switch {
+	case n == 0 || exp[i] >= '0' && exp[i] <= '9':
+		return e, newParseError(MissingKey, exp, i)
+	case strings.HasPrefix(exp[i+n:], "+="):
+		return e, newParseError(Unsupported, exp, i+n)
+	case exported && isEmpty(exp[i+n:]):
+		return e, nil // export of the existing variable
+	case !strings.HasPrefix(exp[i+n:], "="):
+		return e, newParseError(MissingKey, exp, i)
+	}
+	e.Key, i = exp[i:i+n], i+n+1
+
+	// The value.
+	w := &bashWord{str: exp}
+	if i, err = w.parse(i); err != nil {
+		return e, err
+	}
+
+	// Only the comment can be after the value.
+	tail := strings.TrimLeft(exp[i:], " \t")
+	if len(tail) != 0 && tail[0] != '#' {
+		return e, newParseError(IncorrectValue, exp, len(exp)-len(tail))
+	}
+
+	e.raw, e.Quote = w.raw.String(), DoubleQuoted
+	switch {
+	case !w.unquoted && !w.doubleQuoted && w.singleQuoted:
+		e.raw, e.Quote = w.literal.String(), SingleQuoted
+	case !w.singleQuoted && !w.doubleQuoted && !w.escaped:
+		e.Quote = Unquoted
+	}
+
+	e.Value, err = unquote(e.raw, e.Quote, nil)
+	return e, err
+}
+
+// States of the bashJoiner.
+const (
+	bjUnquoted = iota
+	bjSingle
+	bjDouble
+	bjANSI
+	bjDone
+)
+
+// bashJoiner joins the lines of the shell word, it follows the quoting
+// of the word as bashWord does (the errors of the word are detected by
+// bashParse later).
+type bashJoiner struct {
+	state  int
+	escape bool // the line ends with a backslash
+}
+
+func (j *bashJoiner) add(line string) bool {
+	return j.scan("\n" + line)
+}
+
+// scan continues scanning the word with the text and returns true if
+// the word continues on the next line.
+func (j *bashJoiner) scan(text string) bool {
+	i := 0
+	if j.escape {
+		i, j.escape = 1, false // the escaped end of the line
+	}
+
+	for i < len(text) && j.state != bjDone {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 == len(text):
+			j.escape = j.state != bjSingle
+			i++
+		case c == '\\' && j.state != bjSingle:
+			i += 2
+		case j.state == bjSingle:
+			if c == '\'' {
+				j.state = bjUnquoted
+			}
+			i++
+		case j.state == bjANSI:
+			if c == '\'' {
+				j.state = bjUnquoted
+			}
+			i++
+		case c == '`':
+			j.state = bjDone // unsupported
+		case c == '$':
+			i = j.dollar(text, i)
+		case j.state == bjDouble:
+			if c == '"' {
+				j.state = bjUnquoted
+			}
+			i++
+		case strings.IndexByte(" \t\n;&|<>()", c) >= 0:
+			j.state = bjDone // end of the word
+		case c == '\'':
+			j.state, i = bjSingle, i+1
+		case c == '"':
+			j.state, i = bjDouble, i+1
+		default:
+			i++
+		}
+	}
+
+	return j.escape || j.state != bjUnquoted && j.state != bjDone
+}
+
+// dollar skips the `$` sign at the i position with the variable name,
+// or begins the $'...' or $"..." part of the unquoted word.
+func (j *bashJoiner) dollar(text string, i int) int {
+	if i+1 == len(text) {
+		return i + 1
+	}
+
+	switch c := text[i+1]; {
+	case j.state == bjUnquoted && c == '\'':
+		j.state = bjANSI
+		return i + 2
+	case j.state == bjUnquoted && c == '"':
+		j.state = bjDouble
+		return i + 2
+	case c == '{':
+		end := closingBrace(text[i+1:], true)
+		if end < 0 {
+			j.state = bjDone // incorrect value
+			return i
+		}
+		return i + end + 2
+	case c == '(' || strings.IndexByte("$?#!@*-0123456789", c) >= 0:
+		j.state = bjDone // unsupported
+		return i
+	}
+
+	return i + 1 + nameLength(text[i+1:])
+}
+
+// bashUnset parses the `unset KEY` command, the i is the position after
+// the command name.
+func bashUnset(exp string, i int) (e Entry, err error) {
+	tmp := strings.TrimLeft(exp[i:], " \t")
+	n, rest := nameLength(tmp), ""
+	if n != 0 {
+		rest = tmp[n:]
+	}
+
+	switch {
+	case isEmpty(tmp):
+		return e, newParseError(MissingKey, exp, len(exp)-len(tmp))
+	case n == 0 || tmp[0] >= '0' && tmp[0] <= '9' ||
+		!isEmpty(rest) || strings.HasPrefix(rest, "#"):
+		// Options, several names or incorrect name.
+		return e, newParseError(Unsupported, exp, len(exp)-len(tmp))
+	}
+
+	e.Key, e.Action = tmp[:n], Remove
+	return e, nil
+}
+
+// bashWord converts the shell word into the double-quoted value of
+// the Native dialect (the raw), i.e. the literal `\`, `"` and `$`
+// characters are escaped and the variables are kept as is.
+type bashWord struct {
+	str     string
+	raw     strings.Builder // word as the double-quoted value
+	literal strings.Builder // word without variable replacement
+
+	unquoted     bool // the word contains unquoted part
+	singleQuoted bool // the word contains single-quoted part
+	doubleQuoted bool // the word contains double-quoted or $'...' part
+	escaped      bool // the raw contains escaped characters
+}
+
+// parse parses the word beginning at the i position and returns
+// position of the end of the word.
+func (w *bashWord) parse(i int) (int, error) {
+	var err error
+
+	for i < len(w.str) {
+		c := w.str[i]
+		switch {
+		case strings.IndexByte(" \t\n;&|<>()", c) >= 0:
+			return i, nil // end of the word
+		case c == '\\':
+			if i+1 == len(w.str) {
+				return 0, errIncomplete
+			} else if w.str[i+1] != '\n' { // line continuation
+				w.char(w.str[i+1])
+			}
+			w.unquoted, i = true, i+2
+		case c == '\'':
+			end := strings.IndexByte(w.str[i+1:], '\'')
+			if end < 0 {
+				return 0, errIncomplete
+			}
+			for _, b := range []byte(w.str[i+1 : i+1+end]) {
+				w.char(b)
+			}
+			w.singleQuoted, i = true, i+end+2
+		case c == '"' || strings.HasPrefix(w.str[i:], "$\""):
+			if c == '$' {
+				i++ // the $"..." is the same as "..."
+			}
+			w.doubleQuoted = true
+			i, err = w.double(i + 1)
+		case strings.HasPrefix(w.str[i:], "$'"):
+			w.doubleQuoted = true
+			i, err = w.ansi(i + 2)
+		case c == '$':
+			w.unquoted = true
+			i, err = w.dollar(i)
+		case c == '`':
+			return 0, newParseError(Unsupported, w.str, i)
+		default:
+			w.char(c)
+			w.unquoted, i = true, i+1
+		}
+
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return i, nil
+}
+
+// char adds the literal character to the word.
+func (w *bashWord) char(c byte) {
+	if c == '\\' || c == '"' || c == '$' {
+		w.raw.WriteByte('\\')
+		w.escaped = true
+	}
+	w.raw.WriteByte(c)
+	w.literal.WriteByte(c)
+}
+
+// double parses the double-quoted part beginning at the i position
+// (after the quote) and returns position after the closing quote.
+func (w *bashWord) double(i int) (int, error) {
+	for i < len(w.str) {
+		switch c := w.str[i]; {
+		case c == '"':
+			return i + 1, nil
+		case c == '\\' && i+1 < len(w.str):
+			switch next := w.str[i+1]; {
+			case strings.IndexByte("$`\"\\", next) >= 0:
+				w.char(next)
+				i += 2
+			case next == '\n':
+				i += 2 // line continuation
+			default:
+				w.char(c)
+				i++
+			}
+		case c == '$':
+			var err error
+			if i, err = w.dollar(i); err != nil {
+				return 0, err
+			}
+		case c == '`':
+			return 0, newParseError(Unsupported, w.str, i)
+		default:
+			w.char(c)
+			i++
+		}
+	}
+
+	return 0, errIncomplete
+}
+
+// ansi parses the $'...' part beginning at the i position (after the
+// quote) and returns position after the closing quote.
+func (w *bashWord) ansi(i int) (int, error) {
+	escapes := map[byte]string{'a': "\a", 'b': "\b", 'e': "\x1b",
+		'E': "\x1b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t",
+		'v': "\v", '\\': "\\", '\'': "'", '"': "\"", '?': "?"}
+
+	for i < len(w.str) {
+		c := w.str[i]
+		if c == '\'' {
+			return i + 1, nil
+		} else if c != '\\' || i+1 == len(w.str) {
+			w.char(c)
+			i++
+			continue
+		}
+
+		// Escape sequence.
+		next := w.str[i+1]
+		if s, ok := escapes[next]; ok {
+			w.text(s)
+			i += 2
+			continue
+		}
+
+		var base, size int
+		switch {
+		case next >= '0' && next <= '7':
+			base, size = 8, 3
+		case next == 'x':
+			base, size = 16, 2
+		case next == 'u':
+			base, size = 16, 4
+		case next == 'U':
+			base, size = 16, 8
+		default:
+			w.char(c)
+			i++
+			continue
+		}
+
+		// Numeric escape sequence: \NNN, \xHH, \uHHHH or \UHHHHHHHH.
+		start := i + 2
+		if base == 8 {
+			start = i + 1
+		}
+		end := start
+		for end < len(w.str) && end-start < size &&
+			isBaseDigit(w.str[end], base) {
+			end++
+		}
+
+		r, err := strconv.ParseUint(w.str[start:end], base, 32)
+		switch {
+		case err != nil:
+			w.char(c) // not an escape sequence
+			i++
+			continue
+		case next == 'u' || next == 'U':
+			w.text(string(rune(r)))
+		default:
+			w.text(string([]byte{byte(r)}))
+		}
+		i = end
+	}
+
+	return 0, errIncomplete
+}
+
+// text adds the literal text to the word.
+func (w *bashWord) text(s string) {
+	for _, b := range []byte(s) {
+		w.char(b)
+	}
+}
+
+// dollar parses the variable reference beginning at the i position
+// (at the `$` sign) and returns position after the reference.
+func (w *bashWord) dollar(i int) (int, error) {
+	if i+1 == len(w.str) {
+		w.char('$')
+		return i + 1, nil
+	}
+
+	switch c := w.str[i+1]; {
+	case c == '{':
+		end := closingBrace(w.str[i+1:], true)
+		if end < 0 {
+			return 0, newParseError(IncorrectValue, w.str, i)
+		}
+		w.raw.WriteString(w.str[i : i+end+2])
+		w.literal.WriteString(w.str[i : i+end+2])
+		return i + end + 2, nil
+	case c == '(' || strings.IndexByte("$?#!@*-0123456789", c) >= 0:
+		return 0, newParseError(Unsupported, w.str, i)
+	case nameLength(w.str[i+1:]) != 0:
+		n := nameLength(w.str[i+1:])
+		w.raw.WriteString(w.str[i : i+n+1])
+		w.literal.WriteString(w.str[i : i+n+1])
+		return i + n + 1, nil
+	}
+
+	w.char('$')
+	return i + 1, nil
+}
+
+// isBaseDigit returns true if c is a digit of the octal (base is 8)
+// or hexadecimal number.
+func isBaseDigit(c byte, base int) bool {
+	if base == 8 {
+		return c >= '0' && c <= '7'
+	}
+
+	return strings.IndexByte("0123456789abcdefABCDEF", c) >= 0
+}
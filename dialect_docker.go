@@ -0,0 +1,61 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// dockerSyntax implements the Docker dialect, the rules are the same
+// as for the `--env-file` option of the docker CLI:
+//
+//    - lines beginning with `#` (after spaces) are comments;
+//    - the line is split by the first `=` sign, the variable name
+//      cannot contain spaces, the value is taken as is;
+//    - the line without `=` sign takes the value from the environment
+//      or is ignored if the variable isn't set.
+type dockerSyntax struct{}
+
+func (dockerSyntax) open(string) joiner { return nil }
+func (dockerSyntax) eof() int           { return eofError }
+func (dockerSyntax) expands() bool      { return false }
+
+func (dockerSyntax) include(string) (string, bool, bool) {
+	return "", false, false
+}
+
+func (dockerSyntax) empty(exp string) bool {
+	exp = strings.TrimLeftFunc(exp, unicode.IsSpace)
+	return len(exp) == 0 || exp[0] == '#'
+}
+
+func (dockerSyntax) parse(exp string) (e Entry, err error) {
+	if !utf8.ValidString(exp) {
+		pe := newParseError(IncorrectValue, exp, 0)
+		pe.Err = errors.New("invalid utf8 bytes")
+		return e, pe
+	}
+
+	offset := len(exp) - len(strings.TrimLeftFunc(exp, unicode.IsSpace))
+	data := strings.SplitN(exp[offset:], "=", 2)
+
+	key := data[0]
+	switch {
+	case len(key) == 0:
+		return e, newParseError(MissingKey, exp, offset)
+	case strings.ContainsAny(key, " \t"):
+		return e, newParseError(IncorrectKey, exp, offset)
+	case len(data) == 1:
+		// The value is taken from the environment.
+		if value, ok := os.LookupEnv(key); ok {
+			e.Key, e.Value, e.raw = key, value, value
+			e.Action = Inherit
+		}
+		return e, nil
+	}
+
+	e.Key, e.Value, e.raw = key, data[1], data[1]
+	return e, nil
+}
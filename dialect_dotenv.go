@@ -0,0 +1,110 @@
+package env
+
+import (
+	"regexp"
+	"strings"
+)
+
+// The dotenvRegex is the expression of the dotenv package of Node.js
+// (the LINE regular expression).
+var dotenvRegex = regexp.MustCompile(
+	`^\s*(?:export\s+)?([\w.-]+)(?:\s*=\s*?|:\s+?)(\s*'(?:\\'|[^'])*'|` +
+		`\s*"(?:\\"|[^"])*"|\s*` + "`(?:\\\\`|[^`])*`" + `|[^#\r\n]+)?` +
+		`\s*(?:#.*)?$`,
+)
+
+// dotenvSyntax implements the Dotenv dialect, the rules are the same
+// as for the dotenv package of Node.js:
+//
+//    - the key can contain letters, digits, `_`, `.` and `-` and can be
+//      separated from the value by `=` or `:` sign with spaces;
+//    - the value can be quoted by single, double quotes or backticks
+//      and can take several lines, the quotes are removed only if the
+//      value begins and ends with the same quote;
+//    - only \n and \r escapes are interpreted in the double-quoted value;
+//    - the `#` sign begins the comment in the unquoted value;
+//    - the incorrect lines are ignored.
+type dotenvSyntax struct{}
+
+func (dotenvSyntax) eof() int      { return eofSplit }
+func (dotenvSyntax) expands() bool { return false }
+
+func (dotenvSyntax) include(string) (string, bool, bool) {
+	return "", false, false
+}
+
+func (dotenvSyntax) empty(exp string) bool {
+	exp = strings.TrimSpace(exp)
+	return len(exp) == 0 || exp[0] == '#'
+}
+
+func (dotenvSyntax) open(exp string) joiner {
+	// The value of the first line begins with a quote.
+	loc := dotenvRegex.FindStringSubmatchIndex(exp)
+	if loc == nil || loc[4] < 0 {
+		return nil
+	}
+
+	value := exp[loc[4]:]
+	i := loc[4] + len(value) - len(strings.TrimLeft(value, " \t"))
+	if i == len(exp) || strings.IndexByte("'\"`", exp[i]) < 0 {
+		return nil
+	}
+
+	// The closing quote is missing.
+	if j := dotenvJoiner(exp[i]); j.add(exp[i+1:]) {
+		return j
+	}
+
+	return nil
+}
+
+// dotenvJoiner joins the lines of the quoted value up to the closing
+// quote, the escaped quotes are skipped.
+type dotenvJoiner byte
+
+func (q dotenvJoiner) add(line string) bool {
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' && i+1 < len(line) && line[i+1] == byte(q) {
+			i++
+		} else if line[i] == byte(q) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (dotenvSyntax) parse(exp string) (e Entry, err error) {
+	tmp := dotenvRegex.FindStringSubmatch(exp)
+	if tmp == nil {
+		return e, nil // ignored line
+	}
+
+	// Remove the quotes if the value begins and ends with the same quote.
+	value := strings.TrimSpace(tmp[2])
+	doubleQuoted := strings.HasPrefix(value, "\"")
+	if n := len(value); n >= 2 && value[0] == value[n-1] {
+		switch value[0] {
+		case '\'':
+			e.Quote = SingleQuoted
+		case '"':
+			e.Quote = DoubleQuoted
+		case '`':
+			e.Quote = BacktickQuoted
+		}
+
+		if e.Quote != Unquoted {
+			value = value[1 : n-1]
+		}
+	}
+
+	// The escapes are interpreted if the value begins with a double
+	// quote (even if the quotes weren't removed).
+	if doubleQuoted {
+		value = strings.NewReplacer(`\n`, "\n", `\r`, "\r").Replace(value)
+	}
+
+	e.Key, e.Value, e.raw = tmp[1], value, value
+	return e, nil
+}
@@ -0,0 +1,212 @@
+package env
+
+import "strings"
+
+// systemdSyntax implements the Systemd dialect, the rules are the same
+// as for the `EnvironmentFile=` directive of the systemd units:
+//
+//    - lines beginning with `#` or `;` are comments, the comment line
+//      ending with a backslash continues on the next line;
+//    - spaces around the variable name and after the `=` sign are
+//      ignored, the trailing spaces of the unquoted value are removed;
+//    - the backslash in the unquoted value escapes the next character,
+//      the backslash at the end of the line joins the lines;
+//    - the single-quoted value is literal and can take several lines;
+//    - the double-quoted value can take several lines, the backslash
+//      escapes `"`, `\`, "`" and `$` characters and the end of line;
+//    - the quoted values separated by spaces are concatenated;
+//    - the `#` sign after the value isn't a comment;
+//    - the line without `=` sign or with incorrect variable name
+//      is ignored.
+type systemdSyntax struct{}
+
+// States of the systemd env-file parser.
+const (
+	sdPreKey = iota
+	sdKey
+	sdPreValue
+	sdValue
+	sdValueEscape
+	sdSingleQuote
+	sdDoubleQuote
+	sdDoubleQuoteEscape
+	sdComment
+	sdCommentEscape
+)
+
+func (systemdSyntax) eof() int      { return eofJoin }
+func (systemdSyntax) expands() bool { return false }
+
+func (systemdSyntax) include(string) (string, bool, bool) {
+	return "", false, false
+}
+
+func (systemdSyntax) empty(exp string) bool {
+	exp = strings.TrimLeft(exp, " \t\r\n")
+	return len(exp) == 0 || exp[0] == '#' || exp[0] == ';'
+}
+
+func (systemdSyntax) open(exp string) joiner {
+	j := &systemdJoiner{state: sdPreKey}
+	if j.scan(exp) {
+		return j
+	}
+
+	return nil
+}
+
+// systemdJoiner joins the lines while the parser is in the state that
+// continues on the next line (the quoted value or the escaped end of
+// the line).
+type systemdJoiner struct {
+	state int
+}
+
+func (j *systemdJoiner) add(line string) bool {
+	return j.scan("\n" + line)
+}
+
+// scan continues parsing with the text and returns true if the
+// expression continues on the next line.
+func (j *systemdJoiner) scan(text string) bool {
+	_, j.state = systemdScan(text, j.state)
+	switch j.state {
+	case sdValueEscape, sdSingleQuote, sdDoubleQuote,
+		sdDoubleQuoteEscape, sdCommentEscape:
+		return true
+	}
+
+	return false
+}
+
+func (systemdSyntax) parse(exp string) (e Entry, err error) {
+	e, state := systemdScan(exp, sdPreKey)
+	if state < sdPreValue || state > sdDoubleQuoteEscape ||
+		!correctKeyRgx.MatchString(e.Key) {
+		return Entry{}, nil // ignored line
+	}
+
+	e.raw = e.Value
+	return e, nil
+}
+
+// systemdScan parses the exp expression by the rules of the systemd
+// and returns the entry and the final state of the parser. The state
+// is the initial state of the parser, sdPreKey for the new expression
+// (the entry is incomplete for the other states).
+func systemdScan(exp string, state int) (Entry, int) {
+	var (
+		e       Entry
+		key     strings.Builder
+		value   strings.Builder
+		keyEnd  = -1 // the beginning of the trailing spaces of the key
+		trimEnd = -1 // the beginning of the trailing spaces of the value
+	)
+
+	for i := 0; i < len(exp); i++ {
+		c := exp[i]
+		switch state {
+		case sdPreKey:
+			switch {
+			case c == '#' || c == ';':
+				state = sdComment
+			case !strings.ContainsRune(" \t\r\n", rune(c)):
+				state = sdKey
+				key.WriteByte(c)
+			}
+		case sdKey:
+			switch {
+			case c == '\n':
+				key.Reset()
+				state, keyEnd = sdPreKey, -1
+			case c == '=':
+				state = sdPreValue
+			default:
+				if !strings.ContainsRune(" \t\r", rune(c)) {
+					keyEnd = -1
+				} else if keyEnd < 0 {
+					keyEnd = key.Len()
+				}
+				key.WriteByte(c)
+			}
+		case sdPreValue:
+			// The quoting style of the first part of the value.
+			if value.Len() == 0 && e.Quote == Unquoted {
+				switch c {
+				case '\'':
+					e.Quote = SingleQuoted
+				case '"':
+					e.Quote = DoubleQuoted
+				}
+			}
+
+			switch {
+			case c == '\'':
+				state = sdSingleQuote
+			case c == '"':
+				state = sdDoubleQuote
+			case c == '\\':
+				state = sdValueEscape
+			case !strings.ContainsRune(" \t\r\n", rune(c)):
+				state = sdValue
+				value.WriteByte(c)
+			}
+		case sdValue:
+			switch {
+			case c == '\\':
+				state, trimEnd = sdValueEscape, -1
+			default:
+				if !strings.ContainsRune(" \t\r\n", rune(c)) {
+					trimEnd = -1
+				} else if trimEnd < 0 {
+					trimEnd = value.Len()
+				}
+				value.WriteByte(c)
+			}
+		case sdValueEscape:
+			state = sdValue
+			if c != '\n' {
+				value.WriteByte(c)
+			}
+		case sdSingleQuote:
+			if c == '\'' {
+				state = sdPreValue
+			} else {
+				value.WriteByte(c)
+			}
+		case sdDoubleQuote:
+			switch c {
+			case '"':
+				state = sdPreValue
+			case '\\':
+				state = sdDoubleQuoteEscape
+			default:
+				value.WriteByte(c)
+			}
+		case sdDoubleQuoteEscape:
+			state = sdDoubleQuote
+			if strings.IndexByte("\"\\`$", c) >= 0 {
+				value.WriteByte(c)
+			} else if c != '\n' {
+				value.WriteByte('\\')
+				value.WriteByte(c)
+			}
+		case sdComment:
+			if c == '\\' {
+				state = sdCommentEscape
+			}
+		case sdCommentEscape:
+			state = sdComment
+		}
+	}
+
+	e.Key, e.Value = key.String(), value.String()
+	if keyEnd >= 0 {
+		e.Key = e.Key[:keyEnd]
+	}
+	if state == sdValue && trimEnd >= 0 {
+		e.Value = e.Value[:trimEnd]
+	}
+
+	return e, state
+}
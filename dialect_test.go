@@ -0,0 +1,272 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// dialectTest is a conformance test of the dialect: the data must be
+// parsed to the result or fail with the reason.
+type dialectTest struct {
+	data   string
+	result map[string]string
+	reason Reason
+}
+
+// dialectTests are conformance tests for each dialect.
+var dialectTests = map[Format][]dialectTest{
+	Native: {
+		{"KEY=value # comment", map[string]string{"KEY": "value"}, ""},
+		{"export KEY='a $B'", map[string]string{"KEY": "a $B"}, ""},
+		{`KEY="a\tb"`, map[string]string{"KEY": "a\tb"}, ""},
+		{"KEY=\"a\nb\"", map[string]string{"KEY": "a\nb"}, ""},
+		{"#include? none.env", map[string]string{}, ""},
+		{"DIALECT_SET\nunset KEY", map[string]string{"DIALECT_SET": "docker",
+			"KEY": ""}, ""},
+		{"KEY= value", nil, IncorrectValue},
+		{"KEY='a", nil, UnclosedQuote},
+	},
+	Docker: {
+		{"KEY=value", map[string]string{"KEY": "value"}, ""},
+		{`KEY="quoted"`, map[string]string{"KEY": `"quoted"`}, ""},
+		{"KEY=a # b", map[string]string{"KEY": "a # b"}, ""},
+		{"  KEY=  a  ", map[string]string{"KEY": "  a  "}, ""},
+		{"KEY=${HOME}", map[string]string{"KEY": "${HOME}"}, ""},
+		{"KEY=a=b", map[string]string{"KEY": "a=b"}, ""},
+		{"KEY=", map[string]string{"KEY": ""}, ""},
+		{"# comment\n\n  # comment", map[string]string{}, ""},
+		{"KEY='a\nb'", map[string]string{"KEY": "'a"}, ""},
+		{"DIALECT_SET\nDIALECT_UNSET",
+			map[string]string{"DIALECT_SET": "docker"}, ""},
+		{"\ufeffKEY=value", map[string]string{"KEY": "value"}, ""},
+		{"export KEY=value", nil, IncorrectKey},
+		{"=value", nil, MissingKey},
+		{"KEY=\xff", nil, IncorrectValue},
+	},
+	Systemd: {
+		{"KEY=value", map[string]string{"KEY": "value"}, ""},
+		{"  KEY  =  value  ", map[string]string{"KEY": "value"}, ""},
+		{"KEY=a # b", map[string]string{"KEY": "a # b"}, ""},
+		{"; comment\n# comment", map[string]string{}, ""},
+		{`KEY='a $B \n'`, map[string]string{"KEY": `a $B \n`}, ""},
+		{`KEY="a \"b\" \$ \\ \n"`,
+			map[string]string{"KEY": `a "b" $ \ \n`}, ""},
+		{"KEY=\"a\nb\"", map[string]string{"KEY": "a\nb"}, ""},
+		{"KEY=one\\\ntwo", map[string]string{"KEY": "onetwo"}, ""},
+		{`KEY="a" 'b' c`, map[string]string{"KEY": "abc"}, ""},
+		{`KEY=a\ b\tc`, map[string]string{"KEY": "a btc"}, ""},
+		{"KEY=", map[string]string{"KEY": ""}, ""},
+		{`KEY="unclosed`, map[string]string{"KEY": "unclosed"}, ""},
+		{"NOEQUALS\n1KEY=value", map[string]string{}, ""},
+		{"# comment \\\nKEY=value", map[string]string{}, ""},
+	},
+	Bash: {
+		{"KEY=value", map[string]string{"KEY": "value"}, ""},
+		{"export KEY=value # comment",
+			map[string]string{"KEY": "value"}, ""},
+		{`KEY='a $B \n'`, map[string]string{"KEY": `a $B \n`}, ""},
+		{`KEY="a \"b\" \n \$c"`,
+			map[string]string{"KEY": `a "b" \n $c`}, ""},
+		{`KEY=a\ b`, map[string]string{"KEY": "a b"}, ""},
+		{`KEY=$'a\tb\x41\101é\''`,
+			map[string]string{"KEY": "a\tbAAé'"}, ""},
+		{`KEY=a'b'"c"`, map[string]string{"KEY": "abc"}, ""},
+		{"KEY=\"a\nb\"", map[string]string{"KEY": "a\nb"}, ""},
+		{"KEY=one\\\ntwo", map[string]string{"KEY": "onetwo"}, ""},
+		{"KEY=", map[string]string{"KEY": ""}, ""},
+		{"KEY=${B:-c}$D", map[string]string{"KEY": "${B:-c}$D"}, ""},
+		{"#include none.env\nexport B", map[string]string{}, ""},
+		{"unset KEY # comment", map[string]string{"KEY": ""}, ""},
+		{"unset=value", map[string]string{"unset": "value"}, ""},
+		{"unset -v KEY", nil, Unsupported},
+		{"KEY = value", nil, MissingKey},
+		{"KEY=$(whoami)", nil, Unsupported},
+		{"KEY=`whoami`", nil, Unsupported},
+		{"KEY=$1", nil, Unsupported},
+		{"KEY+=value", nil, Unsupported},
+		{"KEY=a b", nil, IncorrectValue},
+		{"KEY='a", nil, UnclosedQuote},
+	},
+	Dotenv: {
+		{"KEY=value", map[string]string{"KEY": "value"}, ""},
+		{"KEY = value", map[string]string{"KEY": "value"}, ""},
+		{"KEY: value", map[string]string{"KEY": "value"}, ""},
+		{"export KEY=value", map[string]string{"KEY": "value"}, ""},
+		{"KEY=a # b", map[string]string{"KEY": "a"}, ""},
+		{"KEY=a#b", map[string]string{"KEY": "a"}, ""},
+		{`KEY='a \n'`, map[string]string{"KEY": `a \n`}, ""},
+		{`KEY="a \n"`, map[string]string{"KEY": "a \n"}, ""},
+		{"KEY=`a`", map[string]string{"KEY": "a"}, ""},
+		{"KEY=\"a\nb\"", map[string]string{"KEY": "a\nb"}, ""},
+		{"app.name-x=1", map[string]string{"app.name-x": "1"}, ""},
+		{"KEY=", map[string]string{"KEY": ""}, ""},
+		{"KEY=${HOME}", map[string]string{"KEY": "${HOME}"}, ""},
+		{`KEY='it\'s'`, map[string]string{"KEY": `it\'s`}, ""},
+		{`KEY="a" b`, map[string]string{"KEY": `"a" b`}, ""},
+		{"not a pair\n# comment", map[string]string{}, ""},
+		{"KEY=\"a\nNEXT=1",
+			map[string]string{"KEY": `"a`, "NEXT": "1"}, ""},
+	},
+}
+
+// TestDialects tests the conformance of the dialects.
+func TestDialects(t *testing.T) {
+	Clear()
+	Set("DIALECT_SET", "docker")
+
+	for f, tests := range dialectTests {
+		for _, test := range tests {
+			entries, err := Parse(strings.NewReader(test.data), Dialect(f))
+
+			var pe *ParseError
+			if test.reason != "" {
+				if !errors.As(err, &pe) || pe.Reason != test.reason {
+					t.Errorf("%s: for `%q` expected `%s` but returns %v.",
+						f, test.data, test.reason, err)
+				}
+				continue
+			} else if err != nil {
+				t.Errorf("%s: for `%q` returns %v.", f, test.data, err)
+				continue
+			}
+
+			result := make(map[string]string)
+			for _, e := range entries {
+				result[e.Key] = e.Value
+			}
+
+			if !reflect.DeepEqual(result, test.result) {
+				t.Errorf("%s: for `%q` expected %q but returns %q.",
+					f, test.data, test.result, result)
+			}
+		}
+	}
+}
+
+// TestDialectLoad tests the variable replacement for the dialects.
+func TestDialectLoad(t *testing.T) {
+	var tests = map[Format]string{
+		Native:  "HOST:8080",
+		Docker:  "${HOST}:8080",
+		Systemd: "${HOST}:8080",
+		Bash:    "HOST:8080",
+		Dotenv:  "${HOST}:8080",
+	}
+
+	for f, value := range tests {
+		Clear()
+		Set("HOST", "HOST")
+		err := LoadReader(strings.NewReader("ADDR=${HOST}:8080"), Dialect(f))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v := Get("ADDR"); v != value {
+			t.Errorf("%s: expected `%s` but returns `%s`.", f, value, v)
+		}
+	}
+
+	// The bash values with different quoting.
+	Clear()
+	data := `A=1
+B="$A \$A"'$A'$'\n'"${C:-2}"
+`
+	if err := LoadReader(strings.NewReader(data), Dialect(Bash)); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := Get("B"); v != "1 $A$A\n2" {
+		t.Errorf("Expected `1 $A$A\\n2` but returns `%q`.", v)
+	}
+}
+
+// TestDialectQuote tests the quoting style of the dialect entries.
+func TestDialectQuote(t *testing.T) {
+	var tests = map[Format]map[string]Quoting{
+		Systemd: {`KEY=a`: Unquoted, `KEY='a'`: SingleQuoted,
+			`KEY="a" b`: DoubleQuoted},
+		Bash: {`KEY=a`: Unquoted, `KEY='a''b'`: SingleQuoted,
+			`KEY="a"`: DoubleQuoted, `KEY=a\$`: DoubleQuoted},
+		Dotenv: {`KEY=a`: Unquoted, `KEY='a'`: SingleQuoted,
+			`KEY="a"`: DoubleQuoted, "KEY=`a`": BacktickQuoted},
+	}
+
+	for f, items := range tests {
+		for data, quote := range items {
+			entries, err := Parse(strings.NewReader(data), Dialect(f))
+			if err != nil || len(entries) != 1 {
+				t.Fatalf("%s: for `%s` returns %v.", f, data, err)
+			}
+
+			if entries[0].Quote != quote {
+				t.Errorf("%s: for `%s` expected %s but returns %s.",
+					f, data, quote, entries[0].Quote)
+			}
+		}
+	}
+}
+
+// TestDialectJoin tests the joiners of the dialects: the number of the
+// lines of the first expression of the data.
+func TestDialectJoin(t *testing.T) {
+	var tests = map[Format]map[string]int{
+		Native: {
+			"KEY=value\nNEXT=1":           1,
+			"KEY=\"a\nb\\\"\nc\"\nNEXT=1": 3,
+			"KEY='a\\\nb'\nNEXT=1":        2,
+			"KEY=a\\\nb\\\nc\nNEXT=1":     3,
+			"KEY=a\\\nb # c\\\nNEXT=1":    2,
+			"KEY=a\\\\\nNEXT=1":           1,
+			"KEY<<EOF\na\n EOF\nEOF\nN=1": 4,
+		},
+		Systemd: {
+			"KEY=a\\\nb\\\nc\nNEXT=1":   3,
+			"KEY=\"a\\\"\nb\"\nNEXT=1":  2,
+			"KEY='a\n\"b'\nNEXT=1":      2,
+			"# a\\\nb\\\nc\nNEXT=1":     3,
+			"KEY=\"a\\\n\\\nb\"\nN=1":   3,
+			"KEY=\"b\" 'c\nd'\nN=1":     2,
+			"KEY=value\n\"NEXT=1\"\n":   1,
+			"KEY=\"a\\\\\"\nNEXT=\"1\"": 1,
+		},
+		Bash: {
+			"KEY=a\\\nb\nNEXT=1":             2,
+			"KEY='a\\\nb'\nNEXT=1":           2,
+			"KEY=\"a\\\"\nb\"\nNEXT=1":       2,
+			"KEY=$'a\\'\nb'\nNEXT=1":         2,
+			"KEY=\"${A}\n$B\"\nNEXT=1":       2,
+			"KEY=a'b\nc'\"d\ne\"\nNEXT=1":    3,
+			"KEY=a 'b\nNEXT=1":               1,
+			"KEY=\"a `b`\nc\"\nNEXT=1":       1,
+			"export KEY=$\"a\n\"b'\nc'\nN=1": 3,
+		},
+		Dotenv: {
+			"KEY=\"a\nb\"\nNEXT=1":     2,
+			"KEY=`a\nb\\`\nc`\nNEXT=1": 3,
+			"KEY='a\\\n'\nNEXT=1":      2,
+			"KEY=a\"\nNEXT=1":          1,
+		},
+	}
+
+	for f, items := range tests {
+		for data, n := range items {
+			lines := strings.Split(data, "\n")
+			count := 1
+			if j := f.syntax().open(lines[0]); j != nil {
+				for count < len(lines) {
+					count++
+					if !j.add(lines[count-1]) {
+						break
+					}
+				}
+			}
+
+			if count != n {
+				t.Errorf("%s: for `%q` expected %d lines but returns %d.",
+					f, data, n, count)
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DuplicateMode defines which definition of the key is used if the key
+// is defined several times in the env-file.
+type DuplicateMode int
+
+// Modes of the duplicate keys handling.
+const (
+	// DefaultDuplicates uses the first definition of the key for Load
+	// and LoadSafe functions and the last one for Update and UpdateSafe
+	// functions, as the values of the environment are handled.
+	DefaultDuplicates DuplicateMode = iota
+
+	// LastWins uses the last definition of the key.
+	LastWins
+
+	// FirstWins uses the first definition of the key.
+	FirstWins
+
+	// RejectDuplicates returns the ParseError with DuplicateKey reason
+	// for the repeated definition of the key.
+	RejectDuplicates
+)
+
+// OnDuplicate sets the handling of the keys defined several times
+// in the env-file (including the included env-files).
+//
+// Example:
+//
+//    // The env-file with the duplicate keys is a mistake.
+//    err := env.Load(".env", env.OnDuplicate(env.RejectDuplicates))
+//    if err != nil {
+//        // something went wrong
+//    }
+func OnDuplicate(mode DuplicateMode) Option {
+	return func(o *options) {
+		o.duplicates = mode
+	}
+}
+
+// Entries is a list of the entries of the env-file.
+type Entries []Entry
+
+// Duplicate is a repeated definition of the key in the env-file.
+type Duplicate struct {
+	Key      string // variable name
+	Previous Entry  // the previous definition of the key
+	Current  Entry  // the repeated definition of the key
+}
+
+// String returns the description of the duplicate as:
+// KEY: filename:line and filename:line.
+func (d Duplicate) String() string {
+	return fmt.Sprintf("%s: %s and %s", d.Key,
+		position(d.Previous), position(d.Current))
+}
+
+// Duplicates returns the repeated definitions of the keys in the order
+// they are written, each definition is reported with the previous one.
+//
+// Example:
+//
+//    entries, err := env.ParseFile(".env")
+//    if err != nil {
+//        // something went wrong
+//    }
+//
+//    for _, d := range entries.Duplicates() {
+//        log.Println(d) // like: PORT: .env:2 and .env:7
+//    }
+func (entries Entries) Duplicates() []Duplicate {
+	var (
+		result []Duplicate
+		seen   = make(map[string]int, len(entries))
+	)
+
+	for i, e := range entries {
+		if e.Action != Assign {
+			continue // directive isn't a definition
+		} else if j, ok := seen[e.Key]; ok {
+			result = append(result, Duplicate{
+				Key:      e.Key,
+				Previous: entries[j],
+				Current:  e,
+			})
+		}
+		seen[e.Key] = i
+	}
+
+	return result
+}
+
+// position returns position of the entry as filename:line.
+func position(e Entry) string {
+	if len(e.Filename) == 0 {
+		return fmt.Sprintf("line %d", e.Line)
+	}
+
+	return fmt.Sprintf("%s:%d", e.Filename, e.Line)
+}
+
+// unique returns the entries with one definition of each key according
+// to the mode: the first one for FirstWins and the last one for LastWins.
+// The entries are returned as is for other modes, the directives are
+// always kept.
+func unique(entries []Entry, mode DuplicateMode) []Entry {
+	if mode != FirstWins && mode != LastWins {
+		return entries
+	}
+
+	index := make(map[string]int, len(entries))
+	for i, e := range entries {
+		_, ok := index[e.Key]
+		if e.Action == Assign && (!ok || mode == LastWins) {
+			index[e.Key] = i
+		}
+	}
+
+	result := make([]Entry, 0, len(index))
+	for i, e := range entries {
+		if e.Action != Assign || index[e.Key] == i {
+			result = append(result, e)
+		}
+	}
+
+	return result
+}
+
+// duplicateError returns ParseError for the repeated definition of the
+// key, the exp is the expression of the current definition.
+func duplicateError(previous, current Entry, exp string) *ParseError {
+	offset := len(exp) - len(strings.TrimLeft(exp, " \t"))
+	pe := newParseError(DuplicateKey, exp, offset)
+	pe.Filename, pe.Line = current.Filename, current.Line+pe.Line-1
+	pe.Err = fmt.Errorf("%s is already defined at %s",
+		previous.Key, position(previous))
+
+	return pe
+}
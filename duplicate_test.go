@@ -0,0 +1,116 @@
+package env
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestEntriesDuplicates tests Duplicates method.
+func TestEntriesDuplicates(t *testing.T) {
+	var tests = []string{
+		"HOST: ./fixtures/duplicate.env:2 and ./fixtures/duplicate.env:4",
+		"HOST: ./fixtures/duplicate.env:4 and ./fixtures/duplicate.env:6",
+	}
+
+	entries, err := ParseFile("./fixtures/duplicate.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := entries.Duplicates()
+	if len(result) != len(tests) {
+		t.Fatalf("Expected %d duplicates but returns %d.",
+			len(tests), len(result))
+	}
+
+	for i, test := range tests {
+		if v := result[i].String(); v != test {
+			t.Errorf("Expected `%s` but returns `%s`.", test, v)
+		}
+	}
+
+	// Duplicates of the included env-files.
+	entries, err = ParseFile("./fixtures/include/main.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result = entries.Duplicates()
+	if len(result) != 3 || result[2].Key != "PORT" ||
+		result[2].Previous.Filename != "fixtures/include/base.env" ||
+		result[2].Current.Filename != "fixtures/include/local.env" {
+		t.Errorf("Incorrect duplicates of the included files: %v.", result)
+	}
+}
+
+// TestOnDuplicate tests OnDuplicate option.
+func TestOnDuplicate(t *testing.T) {
+	type sample struct {
+		mode   DuplicateMode
+		update bool
+		host   string
+	}
+
+	var tests = []sample{
+		{DefaultDuplicates, false, "localhost"},
+		{DefaultDuplicates, true, "127.0.0.1"},
+		{FirstWins, true, "localhost"},
+		{LastWins, false, "127.0.0.1"},
+	}
+
+	for _, test := range tests {
+		var err error
+
+		Clear()
+		opt := OnDuplicate(test.mode)
+		if test.update {
+			err = Update("./fixtures/duplicate.env", opt)
+		} else {
+			err = Load("./fixtures/duplicate.env", opt)
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		url := "http://" + test.host + ":8080/"
+		if v := Get("HOST"); v != test.host {
+			t.Errorf("Expected `%s` but returns `%s`.", test.host, v)
+		} else if v := Get("URL"); v != url {
+			t.Errorf("Expected `%s` but returns `%s`.", url, v)
+		}
+	}
+}
+
+// TestRejectDuplicates tests OnDuplicate option with
+// RejectDuplicates mode.
+func TestRejectDuplicates(t *testing.T) {
+	var pe *ParseError
+
+	Clear()
+	opt := OnDuplicate(RejectDuplicates)
+	err := Load("./fixtures/duplicate.env", opt)
+	if !errors.As(err, &pe) || pe.Reason != DuplicateKey || pe.Line != 4 {
+		t.Fatalf("Expected duplicate key at line 4 but returns %v.", err)
+	}
+
+	expected := "HOST is already defined at ./fixtures/duplicate.env:2"
+	if pe.Err == nil || pe.Err.Error() != expected {
+		t.Errorf("Expected `%s` but returns `%v`.", expected, pe.Err)
+	}
+
+	if v := Get("HOST"); v != "" {
+		t.Errorf("The env-file with duplicates was loaded: %s.", v)
+	}
+
+	// The repeated definitions are skipped in lenient mode.
+	var list ParseErrors
+	err = Load("./fixtures/duplicate.env", opt, Lenient())
+	if !errors.As(err, &list) || len(list) != 2 || list[1].Line != 6 {
+		t.Errorf("Expected two duplicates but returns %v.", err)
+	}
+
+	if v := Get("HOST"); v != "localhost" {
+		t.Errorf("Expected `localhost` but returns `%s`.", v)
+	}
+}
@@ -14,6 +14,14 @@ type Marshaler interface {
 	MarshalENV() ([]string, error)
 }
 
+// MarshalerTo is the interface implemented by types that can marshal
+// themselves into the destination of variables. It takes precedence over
+// the Marshaler interface: the MarshalTo passes its destination, the other
+// functions pass the environment of the process.
+type MarshalerTo interface {
+	MarshalENVTo(dst Environment) ([]string, error)
+}
+
 // marshalENV saves obj into environment data.
 //
 // marshalENV method supports the following field's types: int, int8, int16,
@@ -23,7 +31,10 @@ type Marshaler interface {
 // processed recursively.
 //
 // For other filed's types (like chan, map ...) will be returned an error.
-func marshalENV(obj interface{}, pfx string) ([]string, error) {
+//
+// The keys of the tags are validated according to the options, the
+// values are stored into destination of the options.
+func marshalENV(obj interface{}, pfx string, o *options) ([]string, error) {
 	var (
 		err    error
 		result []string
@@ -40,8 +51,16 @@ func marshalENV(obj interface{}, pfx string) ([]string, error) {
 		return []string{}, errors.New("object isn't a struct")
 	}
 
-	// Implements Marshaler interface.
-	if inst.Implements((*Marshaler)(nil)) {
+	// Implements MarshalerTo or Marshaler interface.
+	if inst.Implements((*MarshalerTo)(nil)) {
+		m := inst.Ptr.MethodByName("MarshalENVTo")
+		tmp := m.Call([]reflect.Value{reflect.ValueOf(o.destination())})
+		value := tmp[0].Interface()
+		if err := tmp[1].Interface(); err != nil {
+			return []string{}, fmt.Errorf("marshal: %v", err)
+		}
+		return value.([]string), nil
+	} else if inst.Implements((*Marshaler)(nil)) {
 		// Try to run custom MarshalENV function.
 		if m := inst.Ptr.MethodByName("MarshalENV"); m.IsValid() {
 			tmp := m.Call([]reflect.Value{})
@@ -57,7 +76,11 @@ func marshalENV(obj interface{}, pfx string) ([]string, error) {
 	// Walk through the fields.
 	result = make([]string, 0, inst.Value.NumField()) // -1
 	for i := 0; i < inst.Value.NumField(); i++ {
-		var key, value, sep string
+		var (
+			key, value, sep string
+			tag             fieldTag
+		)
+
 		field := inst.Value.Type().Field(i)
 		item := inst.Value.FieldByName(field.Name)
 
@@ -66,10 +89,11 @@ func marshalENV(obj interface{}, pfx string) ([]string, error) {
 			item = item.Elem()
 		}
 
-		key, _, sep, err = splitFieldTag(field.Tag.Get("env"))
+		tag, err = parseFieldTag(field.Tag.Get("env"), o.validKey)
 		if err != nil {
 			return []string{}, err
 		}
+		key, sep = tag.key, tag.sep
 
 		if len(key) == 0 {
 			key = field.Name
@@ -90,7 +114,7 @@ func marshalENV(obj interface{}, pfx string) ([]string, error) {
 
 			// Another struct.
 			p := fmt.Sprintf("%s%s_", pfx, key)
-			value, err := marshalENV(item.Interface(), p)
+			value, err := marshalENV(item.Interface(), p, o)
 			if err != nil {
 				return result, err
 			}
@@ -106,7 +130,7 @@ func marshalENV(obj interface{}, pfx string) ([]string, error) {
 
 		// Set into environment and add to result list.
 		key = fmt.Sprintf("%s%s", pfx, key)
-		err = Set(key, value)
+		err = o.setenv(key, value)
 		if err != nil {
 			return result, err
 		}
@@ -40,7 +40,7 @@ func (c *CustomMarshal) MarshalENV() ([]string, error) {
 func TestMarshalENVNilPointer(t *testing.T) {
 	type Empty struct{}
 	var value *Empty
-	if _, err := marshalENV(value, ""); err == nil {
+	if _, err := marshalENV(value, "", &options{}); err == nil {
 		t.Error("exception expected for an uninitialized object")
 	}
 }
@@ -48,7 +48,7 @@ func TestMarshalENVNilPointer(t *testing.T) {
 // TestMarshalENVNotStruct tests marshalENV function for not struct.
 func TestMarshalNotStruct(t *testing.T) {
 	var value string
-	if _, err := marshalENV(value, ""); err == nil {
+	if _, err := marshalENV(value, "", &options{}); err == nil {
 		t.Error("exception expected for an object other than structure")
 	}
 }
@@ -70,7 +70,7 @@ func TestMarshalENV(t *testing.T) {
 	}
 
 	Clear()
-	_, err := marshalENV(value, "")
+	_, err := marshalENV(value, "", &options{})
 	if err != nil {
 		t.Error(err)
 	}
@@ -109,7 +109,7 @@ func TestMarshalENVPtr(t *testing.T) {
 	}
 
 	Clear()
-	_, err := marshalENV(value, "")
+	_, err := marshalENV(value, "", &options{})
 	if err != nil {
 		t.Error(err)
 	}
@@ -142,7 +142,7 @@ func TestMarshalENVCustom(t *testing.T) {
 	}
 
 	Clear()
-	_, err := marshalENV(scope, "")
+	_, err := marshalENV(scope, "", &options{})
 	if err != nil {
 		t.Error(err)
 	}
@@ -171,7 +171,7 @@ func TestMarshalENVCustomPtr(t *testing.T) {
 	}
 
 	Clear()
-	_, err := marshalENV(scope, "")
+	_, err := marshalENV(scope, "", &options{})
 	if err != nil {
 		t.Error(err)
 	}
@@ -398,7 +398,7 @@ func TestMarshalENVNumberPtr(t *testing.T) {
 
 	// ...
 	Clear()
-	_, err := marshalENV(value, "")
+	_, err := marshalENV(value, "", &options{})
 	if err != nil {
 		t.Error(err)
 	}
@@ -432,7 +432,7 @@ func TestMarshalENVBoolPtr(t *testing.T) {
 
 	// ...
 	Clear()
-	_, err := marshalENV(value, "")
+	_, err := marshalENV(value, "", &options{})
 	if err != nil {
 		t.Error(err)
 	}
@@ -459,7 +459,7 @@ func TestMarshalENVStringPtr(t *testing.T) {
 
 	// ...
 	Clear()
-	_, err := marshalENV(value, "")
+	_, err := marshalENV(value, "", &options{})
 	if err != nil {
 		t.Error(err)
 	}
@@ -504,7 +504,7 @@ func TestMarshalENVSlice(t *testing.T) {
 	// 	return strings.Trim(strings.Replace(fmt.Sprint(v), " ", ":", -1), "[]")
 	// }
 
-	_, err := marshalENV(s, "")
+	_, err := marshalENV(s, "", &options{})
 	if err != nil {
 		t.Error(err)
 	}
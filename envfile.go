@@ -0,0 +1,437 @@
+package env
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// EnvFile is a document model of the env-file that allows to change
+// the entries without losing comments, empty lines, include directives,
+// `export` prefixes, order and quoting of the other entries. The lines
+// that weren't changed are returned byte-for-byte identical.
+//
+// Examples:
+//
+// Suppose that there is .env file with data:
+//
+//    # Server.
+//    export HOST=0.0.0.0
+//    PORT='8080' # default port
+//
+// Change the port and save the file:
+//
+//    f, err := env.ReadEnvFile(".env")
+//    if err != nil {
+//        // something went wrong
+//    }
+//
+//    f.Set("PORT", "80")
+//    if err := f.Save(".env"); err != nil {
+//        // something went wrong
+//    }
+//
+//    // The .env file:
+//    // # Server.
+//    // export HOST=0.0.0.0
+//    // PORT='80' # default port
+//
+// The opts can set the Dialect of the env-file and the KeyPolicy of the
+// keys. The changed entries of the dialects other than Native are
+// written as `KEY=value` (the `export` prefix is kept, the inline
+// comment is removed) with the quoting that the dialect reads back
+// to the same value.
+type EnvFile struct {
+	lines []*envLine
+	eol   string   // line ending of the file
+	o     *options // dialect and key policy
+}
+
+// envLine is an entry of the env-file or a line (comment, empty line,
+// include directive) without entry. The entry can take several lines.
+type envLine struct {
+	head  string // text before the value or whole line without entry
+	raw   string // value as it is written in the file
+	tail  string // text after the value, including line ending
+	key   string // empty for line without entry
+	value string
+	quote Quoting
+	other syntax // dialect of the entry, nil for Native
+}
+
+// NewEnvFile parses env-file data and returns its document model.
+// Returns an error for the first incorrect expression.
+func NewEnvFile(data []byte, opts ...Option) (*EnvFile, error) {
+	var (
+		f      = &EnvFile{eol: "\n", o: newOptions(false, false, opts)}
+		syntax = f.o.syntax()
+		text   = string(data)
+		lines  []string
+		bom    string
+	)
+
+	// The byte order mark isn't a part of the first expression,
+	// but it's kept in the head of the first line.
+	if strings.HasPrefix(text, "\ufeff") {
+		bom, text = text[:3], text[3:]
+	}
+
+	// Split into lines with line endings.
+	for len(text) != 0 {
+		i := strings.IndexByte(text, '\n') + 1
+		if i == 0 {
+			i = len(text)
+		}
+		lines, text = append(lines, text[:i]), text[i:]
+	}
+
+	if len(lines) != 0 && strings.HasSuffix(lines[0], "\r\n") {
+		f.eol = "\r\n"
+	}
+
+	for i := 0; i < len(lines); i++ {
+		raw, exp, line := lines[i], trimEOL(lines[i]), i+1
+		if _, _, ok := syntax.include(exp); ok || syntax.empty(exp) {
+			f.lines = append(f.lines, &envLine{head: raw})
+			continue
+		}
+
+		// Multiline value: join the lines up to the closing quote
+		// or while the line ends with a backslash (depends on the
+		// dialect), the joiner checks the new line only.
+		if j := syntax.open(exp); j != nil {
+			start, parts := i, []string{exp}
+			for i++; i < len(lines); i++ {
+				parts = append(parts, trimEOL(lines[i]))
+				if !j.add(parts[len(parts)-1]) {
+					break
+				}
+			}
+
+			// The end of the data: the syntax reports the problem
+			// or the lines are parsed separately.
+			if i == len(lines) && syntax.eof() == eofSplit {
+				i = start
+			} else if i == len(lines) {
+				i--
+			}
+			raw = strings.Join(lines[start:i+1], "")
+			exp = strings.Join(parts[:i-start+1], "\n")
+		}
+
+		l, err := newEnvLine(syntax, raw, exp)
+		if err != nil {
+			if pe, ok := err.(*ParseError); ok {
+				pe.Line += line - 1
+			}
+			return nil, err
+		}
+		f.lines = append(f.lines, l)
+	}
+
+	if len(bom) != 0 && len(f.lines) == 0 {
+		f.lines = append(f.lines, &envLine{head: bom})
+	} else if len(bom) != 0 {
+		f.lines[0].head = bom + f.lines[0].head
+	}
+
+	return f, nil
+}
+
+// ReadEnvFile reads env-file by name and returns its document model.
+// See NewEnvFile for details.
+func ReadEnvFile(filename string, opts ...Option) (*EnvFile, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := NewEnvFile(data, opts...)
+	if pe, ok := err.(*ParseError); ok {
+		pe.Filename = filename
+	}
+
+	return f, err
+}
+
+// Get returns the value of the key and true if the key is defined.
+// The value of the last definition is returned if the key is defined
+// several times.
+func (f *EnvFile) Get(key string) (string, bool) {
+	for i := len(f.lines) - 1; i >= 0; i-- {
+		if f.lines[i].key == key {
+			return f.lines[i].value, true
+		}
+	}
+
+	return "", false
+}
+
+// Set changes the value of the key keeping the `export` prefix, quoting
+// style (if it's possible for the value, including heredoc block) and
+// inline comment, all definitions of the key are changed. The new key
+// is added to the end of the file. Returns an error if the key or value
+// is incorrect or the value cannot be written in the dialect.
+func (f *EnvFile) Set(key, value string) error {
+	if !f.o.validKey(key) {
+		return fmt.Errorf("incorrect key %s", key)
+	} else if !utf8.ValidString(value) {
+		// The invalid UTF-8 sequences cannot be read back exactly.
+		return fmt.Errorf("incorrect value of %s: invalid UTF-8", key)
+	}
+
+	found := false
+	for _, l := range f.lines {
+		if l.key == key {
+			if err := l.set(value); err != nil {
+				return err
+			}
+			found = true
+		}
+	}
+
+	if !found {
+		// The last line must be terminated.
+		if n := len(f.lines); n != 0 {
+			if last := f.lines[n-1]; !strings.HasSuffix(last.String(), "\n") {
+				last.tail += f.eol
+			}
+		}
+
+		l := &envLine{head: key + "=", tail: f.eol, key: key}
+		if f.o.dialect != Native {
+			l.other = f.o.syntax()
+		}
+
+		if err := l.set(value); err != nil {
+			return err
+		}
+		f.lines = append(f.lines, l)
+	}
+
+	return nil
+}
+
+// Delete removes all definitions of the key, returns false if the key
+// is not defined.
+func (f *EnvFile) Delete(key string) bool {
+	lines := f.lines[:0]
+	for _, l := range f.lines {
+		if l.key != key {
+			lines = append(lines, l)
+		}
+	}
+
+	found := len(lines) != len(f.lines)
+	f.lines = lines
+
+	return found
+}
+
+// Rename changes the name of the key keeping its value and formatting.
+// Returns an error if the new key is incorrect or it's already defined,
+// or the old key is not defined.
+func (f *EnvFile) Rename(oldKey, newKey string) error {
+	if !f.o.validKey(newKey) {
+		return fmt.Errorf("incorrect key %s", newKey)
+	} else if _, ok := f.Get(newKey); ok {
+		return fmt.Errorf("key %s is already defined", newKey)
+	} else if _, ok := f.Get(oldKey); !ok {
+		return fmt.Errorf("key %s is not defined", oldKey)
+	}
+
+	for _, l := range f.lines {
+		if l.key == oldKey {
+			i := strings.LastIndex(l.head, oldKey+"=")
+			switch {
+			case l.other != nil:
+				i = keyIndex(l.head, oldKey)
+			case l.quote == HeredocQuoted:
+				i = strings.Index(l.head, oldKey+"<<")
+			}
+			l.head = l.head[:i] + newKey + l.head[i+len(oldKey):]
+			l.key = newKey
+		}
+	}
+
+	return nil
+}
+
+// Bytes returns the env-file data.
+func (f *EnvFile) Bytes() []byte {
+	var buf bytes.Buffer
+	for _, l := range f.lines {
+		buf.WriteString(l.String())
+	}
+
+	return buf.Bytes()
+}
+
+// WriteTo writes the env-file data into w.
+func (f *EnvFile) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(f.Bytes())
+	return int64(n), err
+}
+
+// Save writes the env-file data into file by name atomically, the
+// permissions of the existing file are preserved.
+func (f *EnvFile) Save(filename string) error {
+	return writeFile(filename, func(w io.Writer) error {
+		_, err := f.WriteTo(w)
+		return err
+	})
+}
+
+// newEnvLine returns a new entry for the raw text of the entry, the exp
+// is the same text without line endings (as the parser reads it).
+func newEnvLine(s syntax, raw, exp string) (*envLine, error) {
+	e, err := s.parse(exp)
+	if err != nil {
+		return nil, err
+	} else if e.Action != Assign {
+		return &envLine{head: raw}, nil // directive is kept as is
+	}
+
+	l, quote := &envLine{key: e.Key, value: e.Value, quote: e.Quote}, e.Quote
+
+	// The entry of other dialect is kept as is up to the change.
+	if _, ok := s.(nativeSyntax); !ok {
+		l.head, l.other = raw, s
+		return l, nil
+	}
+
+	// The heredoc block: the value is written between the first
+	// and the last lines.
+	if quote == HeredocQuoted {
+		start := strings.IndexByte(raw, '\n') + 1
+		end := strings.LastIndexByte(trimEOL(raw), '\n') + 1
+		l.head, l.raw, l.tail = raw[:start], raw[start:end], raw[end:]
+		return l, nil
+	}
+
+	// The value is written right after the `=` sign.
+	start := strings.Index(raw, "=") + 1
+	end := start + strings.IndexAny(raw[start:]+" ", " \t\r\n#")
+	switch {
+	case quote != Unquoted:
+		end = start + closingQuote(raw[start:]) + 1
+	case strings.Contains(exp, "\n"):
+		end = len(trimEOL(raw)) // the value continues on the next lines
+	}
+	l.head, l.raw, l.tail = raw[:start], raw[start:end], raw[end:]
+
+	return l, nil
+}
+
+// set changes the value of the entry. The quoting style is kept if it's
+// possible, the unquoted value is quoted if necessary. Only the comment
+// and line ending are kept after the value.
+//
+// The entry of other dialect is written as `KEY=value` with the first
+// quoting (bare, single or double quotes) that the dialect reads back
+// to the same value, returns an error if there is no such quoting.
+func (l *envLine) set(value string) error {
+	if l.other != nil {
+		return l.setOther(value)
+	}
+
+	if l.quote == HeredocQuoted {
+		_, delimiter, _ := parseHeredoc(l.head)
+		eol := l.head[len(trimEOL(l.head)):]
+		if isHeredocValue(value, delimiter) {
+			l.raw = strings.ReplaceAll(value, "\n", eol) + eol
+			l.value = value
+			return nil
+		}
+
+		// The value cannot be written as heredoc block.
+		i := strings.Index(l.head, l.key+"<<") + len(l.key)
+		l.head, l.tail, l.quote = l.head[:i]+"=", eol, Unquoted
+	}
+
+	switch {
+	case l.quote == SingleQuoted && !strings.Contains(value, "'"):
+		l.raw = "'" + value + "'"
+	case l.quote == DoubleQuoted:
+		l.raw = doubleQuote(value)
+	default:
+		l.raw = quoteValue(value)
+		l.quote = Unquoted
+		if strings.HasPrefix(l.raw, "\"") {
+			l.quote = DoubleQuoted
+		}
+	}
+
+	body := trimEOL(l.tail)
+	eol := l.tail[len(body):]
+	if i := strings.IndexByte(body, '#'); i >= 0 {
+		body = body[len(strings.TrimRight(body[:i], " \t")):]
+	} else {
+		body = ""
+	}
+
+	l.tail, l.value = body+eol, value
+	return nil
+}
+
+// setOther changes the value of the entry of other dialect than Native.
+func (l *envLine) setOther(value string) error {
+	text := l.String()
+	body := strings.TrimRight(text, "\r\n")
+	eol := text[len(body):]
+	if i := strings.IndexAny(body, "\r\n"); i >= 0 {
+		// The multi-line entry: the line ending of the first line.
+		eol = body[i : i+1]
+		if strings.HasPrefix(body[i:], "\r\n") {
+			eol = "\r\n"
+		}
+	}
+
+	i := keyIndex(text, l.key)
+	head := text[:i+len(l.key)] + "="
+	for _, raw := range []string{value, "'" + value + "'", doubleQuote(value)} {
+		exp := head + raw
+		if strings.ContainsAny(raw, "\r\n") || l.other.open(exp) != nil {
+			continue
+		}
+
+		e, err := l.other.parse(exp)
+		if err == nil && e.Action == Assign &&
+			e.Key == l.key && e.Value == value {
+			l.head, l.raw, l.tail, l.value = head, raw, eol, value
+			return nil
+		}
+	}
+
+	return fmt.Errorf("the value of %s cannot be written in the dialect",
+		l.key)
+}
+
+// keyIndex returns position of the key in the text of the entry,
+// the spaces and `export` prefix are skipped.
+func keyIndex(text, key string) int {
+	i := skipSpaces(text, 0)
+	if strings.HasPrefix(text[i:], "export") &&
+		i+6 < len(text) && isSpace(text[i+6]) {
+		i = skipSpaces(text, i+6)
+	}
+
+	if !strings.HasPrefix(text[i:], key) {
+		return strings.Index(text, key)
+	}
+
+	return i
+}
+
+// String returns the text of the entry as it is written in the file.
+func (l *envLine) String() string {
+	return l.head + l.raw + l.tail
+}
+
+// trimEOL returns the line without line ending.
+func trimEOL(line string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+}
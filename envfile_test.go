@@ -0,0 +1,306 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestEnvFileBytes tests that the unchanged EnvFile returns the same
+// data byte-for-byte.
+func TestEnvFileBytes(t *testing.T) {
+	var tests = []string{
+		"",
+		"KEY=value",
+		"KEY=value\n\n# comment\n",
+		"KEY=value\r\nKEY_1='a\r\nb'\r\n",
+		"  export KEY=value  # comment \n\t\n",
+		"HOME # pass-through\nunset TMP\n",
+	}
+
+	data, err := os.ReadFile("./fixtures/editor.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests = append(tests, string(data))
+
+	for _, test := range tests {
+		f, err := NewEnvFile([]byte(test))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v := string(f.Bytes()); v != test {
+			t.Errorf("Expected `%q` but returns `%q`.", test, v)
+		}
+	}
+}
+
+// TestEnvFileGet tests EnvFile.Get method.
+func TestEnvFileGet(t *testing.T) {
+	var tests = map[string]string{
+		"HOST":     "0.0.0.0",
+		"PORT":     "8080",
+		"GREETING": `Hello, "${USER}"!`,
+		"TLS_KEY":  "-----BEGIN KEY-----\nMIIEvQ==\n-----END KEY-----",
+		"DEBUG":    "false",
+	}
+
+	f, err := ReadEnvFile("./fixtures/editor.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, value := range tests {
+		if v, ok := f.Get(key); !ok || v != value {
+			t.Errorf("Incorrect value for `%s` key: `%s`!=`%s`", key, value, v)
+		}
+	}
+
+	if _, ok := f.Get("USER"); ok {
+		t.Error("The USER key must not be defined.")
+	}
+}
+
+// TestEnvFileEdit tests Set, Delete and Rename methods of the EnvFile.
+func TestEnvFileEdit(t *testing.T) {
+	f, err := ReadEnvFile("./fixtures/editor.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.Set("PORT", "80")            // keep quoting and comment
+	f.Set("GREETING", "Hi, $USER") // keep double quotes
+	f.Set("DEBUG", "true value")   // add quotes, keep comment
+	f.Set("TLS_KEY", "none")       // keep double quotes
+	f.Set("NEW_KEY", "new")        // add to the end
+	if !f.Delete("HOST") || f.Delete("HOST") {
+		t.Error("The HOST key must be deleted once.")
+	}
+	if err := f.Rename("NEW_KEY", "LAST_KEY"); err != nil {
+		t.Error(err)
+	}
+
+	expected := strings.Join([]string{
+		"# Server settings.",
+		"PORT='80'   # default port",
+		"",
+		"source? ./local.env",
+		`  GREETING="Hi, \$USER"`,
+		`TLS_KEY="none"`,
+		`DEBUG="true value"#inline`,
+		"LAST_KEY=new",
+		"",
+	}, "\n")
+	if v := string(f.Bytes()); v != expected {
+		t.Errorf("Expected:\n%s\nbut returns:\n%s", expected, v)
+	}
+
+	// Errors.
+	if err := f.Set("1KEY", "value"); err == nil {
+		t.Error("Expected error for incorrect key.")
+	}
+	if err := f.Rename("PORT", "DEBUG"); err == nil {
+		t.Error("Expected error for existing key.")
+	}
+	if err := f.Rename("HOST", "SERVER"); err == nil {
+		t.Error("Expected error for undefined key.")
+	}
+
+	// The result can be read back.
+	name := filepath.Join(t.TempDir(), ".env")
+	if err := f.Save(name); err != nil {
+		t.Fatal(err)
+	}
+
+	Clear()
+	if err := LoadSafe(name); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, value := range map[string]string{
+		"PORT":     "80",
+		"GREETING": "Hi, $USER",
+		"DEBUG":    "true value",
+		"LAST_KEY": "new",
+	} {
+		if v := Get(key); v != value {
+			t.Errorf("Incorrect value for `%s` key: `%s`!=`%s`", key, value, v)
+		}
+	}
+}
+
+// TestEnvFileAppend tests that the new key is added on a new line.
+func TestEnvFileAppend(t *testing.T) {
+	f, err := NewEnvFile([]byte("KEY_0=value\r\nKEY_1=value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.Set("KEY_2", "")
+	expected := "KEY_0=value\r\nKEY_1=value\r\nKEY_2=\"\"\r\n"
+	if v := string(f.Bytes()); v != expected {
+		t.Errorf("Expected `%q` but returns `%q`.", expected, v)
+	}
+}
+
+// TestEnvFileError tests errors of the NewEnvFile function.
+func TestEnvFileError(t *testing.T) {
+	var tests = map[string]int{
+		"KEY=value\n1KEY=value\n":     2,
+		"KEY=value\n\nKEY_1=\"a\nb\n": 3,
+	}
+
+	for data, line := range tests {
+		var pe *ParseError
+		_, err := NewEnvFile([]byte(data))
+		if !errors.As(err, &pe) || pe.Line != line {
+			t.Errorf("Expected error at %d line but returns %v.", line, err)
+		}
+	}
+}
+
+// TestEnvFileHeredoc tests EnvFile with heredoc blocks.
+func TestEnvFileHeredoc(t *testing.T) {
+	data := "# Notes.\nKEY_0<<EOF\nfirst\r\nsecond\nEOF\nKEY_1<<END\nEND\n"
+	f, err := NewEnvFile([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := string(f.Bytes()); v != data {
+		t.Errorf("Expected `%q` but returns `%q`.", data, v)
+	}
+
+	if v, _ := f.Get("KEY_0"); v != "first\nsecond" {
+		t.Errorf("Expected `first\\nsecond` but returns `%q`.", v)
+	}
+
+	f.Set("KEY_0", "one\ntwo")   // keep heredoc block
+	f.Set("KEY_1", "END\nvalue") // the delimiter in the value
+	if err := f.Rename("KEY_0", "NOTES"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# Notes.\nNOTES<<EOF\none\ntwo\nEOF\n" +
+		"KEY_1=\"END\\nvalue\"\n"
+	if v := string(f.Bytes()); v != expected {
+		t.Errorf("Expected `%q` but returns `%q`.", expected, v)
+	}
+}
+
+// TestEnvFileContinuation tests EnvFile for the line continuation.
+func TestEnvFileContinuation(t *testing.T) {
+	data := "KEY_0=first\\\n  second\nKEY_1=value\\\r\n"
+	f, err := NewEnvFile([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := string(f.Bytes()); v != data {
+		t.Errorf("Expected `%q` but returns `%q`.", data, v)
+	}
+
+	if v, _ := f.Get("KEY_0"); v != "first  second" {
+		t.Errorf("Expected `first  second` but returns `%q`.", v)
+	}
+
+	f.Set("KEY_0", "value")
+	expected := "KEY_0=value\nKEY_1=value\\\r\n"
+	if v := string(f.Bytes()); v != expected {
+		t.Errorf("Expected `%q` but returns `%q`.", expected, v)
+	}
+}
+
+// TestEnvFileOptions tests EnvFile with the dialect and key policy.
+func TestEnvFileOptions(t *testing.T) {
+	data := "# Docker.\nKEY_0=\"quoted\" # text\r\nKEY_1\nKEY_2=a b\n"
+	f, err := NewEnvFile([]byte(data), Dialect(Docker))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := string(f.Bytes()); v != data {
+		t.Errorf("Expected `%q` but returns `%q`.", data, v)
+	}
+
+	if v, _ := f.Get("KEY_0"); v != "\"quoted\" # text" {
+		t.Errorf("Expected `\"quoted\" # text` but returns `%q`.", v)
+	}
+
+	f.Set("KEY_0", "'value' # text")
+	f.Set("KEY_3", "x y")
+	if err := f.Rename("KEY_2", "KEY_4"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# Docker.\nKEY_0='value' # text\r\nKEY_1\nKEY_4=a b\n" +
+		"KEY_3=x y\n"
+	if v := string(f.Bytes()); v != expected {
+		t.Errorf("Expected `%q` but returns `%q`.", expected, v)
+	}
+
+	if err := f.Set("KEY_0", "a\nb"); err == nil {
+		t.Error("Expected an error for the multi-line value.")
+	}
+
+	// Key policy.
+	f, err = NewEnvFile([]byte("app.name=demo\n"), Dialect(Dotenv),
+		KeyPolicy(RelaxedKeys))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.Set("app.port", "8080"); err != nil {
+		t.Error(err)
+	}
+
+	if err := f.Rename("app.name", "app-name"); err != nil {
+		t.Error(err)
+	}
+
+	expected = "app-name=demo\napp.port=8080\n"
+	if v := string(f.Bytes()); v != expected {
+		t.Errorf("Expected `%q` but returns `%q`.", expected, v)
+	}
+
+	if _, err := NewEnvFile([]byte("app.name=demo\n")); err == nil {
+		t.Error("Expected an error for the key without KeyPolicy option.")
+	}
+}
+
+// TestEnvFileBOM tests that the byte order mark is kept in the data,
+// but it isn't a part of the first key.
+func TestEnvFileBOM(t *testing.T) {
+	for _, data := range []string{"\ufeffA=1\nB=2\n", "\ufeff"} {
+		f, err := NewEnvFile([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v := string(f.Bytes()); v != data {
+			t.Errorf("Expected `%q` but returns `%q`.", data, v)
+		}
+	}
+
+	f, err := NewEnvFile([]byte("\ufeffA=1\nB=2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok := f.Get("A"); !ok || v != "1" {
+		t.Errorf("Expected `1` but returns `%q`.", v)
+	}
+
+	f.Set("A", "one")
+	if err := f.Rename("A", "C"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "\ufeffC=one\nB=2\n"
+	if v := string(f.Bytes()); v != expected {
+		t.Errorf("Expected `%q` but returns `%q`.", expected, v)
+	}
+}
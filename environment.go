@@ -0,0 +1,109 @@
+package env
+
+import "os"
+
+// Lookuper is the source of the variables, like the environment
+// of the process.
+type Lookuper interface {
+	// LookupEnv returns the value of the variable by key, the ok
+	// is false if the variable isn't set.
+	LookupEnv(key string) (value string, ok bool)
+}
+
+// Environment is the source and destination of the variables.
+type Environment interface {
+	Lookuper
+
+	// Setenv sets the value of the variable by key.
+	Setenv(key, value string) error
+}
+
+// OS returns the environment of the process.
+func OS() Environment {
+	return osEnv{}
+}
+
+// Map returns the environment that keeps the variables in the m map,
+// the m must be initialized to set the variables.
+//
+// Example:
+//
+//    // The variables of the child process.
+//    vars := make(map[string]string)
+//    for _, item := range cmd.Env {
+//        kv := strings.SplitN(item, "=", 2)
+//        vars[kv[0]] = kv[len(kv)-1]
+//    }
+//
+//    var config Config
+//    err := env.UnmarshalFrom(env.Map(vars), &config)
+//    if err != nil {
+//        // something went wrong
+//    }
+func Map(m map[string]string) Environment {
+	return mapEnv(m)
+}
+
+// osEnv is the environment of the process.
+type osEnv struct{}
+
+func (osEnv) LookupEnv(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func (osEnv) Setenv(key, value string) error {
+	return os.Setenv(key, value)
+}
+
+// mapEnv is the environment in the map.
+type mapEnv map[string]string
+
+func (m mapEnv) LookupEnv(key string) (string, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+func (m mapEnv) Setenv(key, value string) error {
+	m[key] = value
+	return nil
+}
+
+// lookupEnv returns the value of the variable from the source
+// of the options (the environment of the process by default).
+func (o *options) lookupEnv(key string) (string, bool) {
+	if o.src == nil {
+		return os.LookupEnv(key)
+	}
+
+	return o.src.LookupEnv(key)
+}
+
+// source returns the source of the options (the environment
+// of the process by default).
+func (o *options) source() Lookuper {
+	if o.src == nil {
+		return OS()
+	}
+
+	return o.src
+}
+
+// destination returns the destination of the options (the
+// environment of the process by default).
+func (o *options) destination() Environment {
+	if o.dst == nil {
+		return OS()
+	}
+
+	return o.dst
+}
+
+// setenv sets the value of the variable into destination
+// of the options (the environment of the process by default).
+func (o *options) setenv(key, value string) error {
+	if o.dst == nil {
+		return os.Setenv(key, value)
+	}
+
+	return o.dst.Setenv(key, value)
+}
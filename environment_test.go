@@ -0,0 +1,149 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// environmentConfig is the structure for the Environment tests.
+type environmentConfig struct {
+	Host  string   `env:"HOST,localhost"`
+	Port  int      `env:"PORT"`
+	Hosts []string `env:"ALLOWED_HOSTS,,:"`
+	DB    struct {
+		Name string `env:"NAME"`
+	} `env:"DB"`
+}
+
+// environmentCustom is the structure with custom methods of the
+// source-aware interfaces.
+type environmentCustom struct {
+	Value string
+}
+
+// UnmarshalENV is never called, the UnmarshalENVFrom is preferred.
+func (c *environmentCustom) UnmarshalENV() error {
+	return errors.New("UnmarshalENV is called")
+}
+
+// UnmarshalENVFrom reads the value from the src.
+func (c *environmentCustom) UnmarshalENVFrom(src Lookuper) error {
+	c.Value, _ = src.LookupEnv("CUSTOM")
+	return nil
+}
+
+// MarshalENV is never called, the MarshalENVTo is preferred.
+func (c *environmentCustom) MarshalENV() ([]string, error) {
+	return nil, errors.New("MarshalENV is called")
+}
+
+// MarshalENVTo writes the value into the dst.
+func (c *environmentCustom) MarshalENVTo(dst Environment) ([]string, error) {
+	return []string{"CUSTOM=" + c.Value}, dst.Setenv("CUSTOM", c.Value)
+}
+
+// TestOS tests OS function.
+func TestOS(t *testing.T) {
+	Clear()
+	e := OS()
+	if err := e.Setenv("KEY", "value"); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok := e.LookupEnv("KEY"); !ok || v != "value" {
+		t.Errorf("Expected `value` but returns `%s`.", v)
+	} else if v := os.Getenv("KEY"); v != "value" {
+		t.Errorf("The variable isn't set into environment: `%s`.", v)
+	}
+}
+
+// TestUnmarshalFrom tests UnmarshalFrom function.
+func TestUnmarshalFrom(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c   environmentConfig
+		src = Map(map[string]string{
+			"PORT":          "8080",
+			"ALLOWED_HOSTS": "a:b",
+			"DB_NAME":       "app",
+		})
+	)
+
+	if err := UnmarshalFrom(src, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Host != "localhost" || c.Port != 8080 || c.DB.Name != "app" ||
+		!reflect.DeepEqual(c.Hosts, []string{"a", "b"}) {
+		t.Errorf("Incorrect values: %v.", c)
+	}
+
+	if err := UnmarshalFrom(src, c); err == nil {
+		t.Error("Expected error for the object that isn't a pointer.")
+	}
+}
+
+// TestMarshalTo tests MarshalTo function.
+func TestMarshalTo(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c    environmentConfig
+		vars = make(map[string]string)
+	)
+
+	c.Host, c.Port, c.Hosts, c.DB.Name = "0.0.0.0", 80, []string{"a"}, "app"
+	if _, err := MarshalTo(Map(vars), &c); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"HOST":          "0.0.0.0",
+		"PORT":          "80",
+		"ALLOWED_HOSTS": "a",
+		"DB_NAME":       "app",
+	}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Errorf("Expected %v but returns %v.", expected, vars)
+	}
+
+	// Read back.
+	var tmp environmentConfig
+	if err := UnmarshalFrom(Map(vars), &tmp); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(tmp, c) {
+		t.Errorf("Expected %v but returns %v.", c, tmp)
+	}
+}
+
+// TestCustomFromTo tests that UnmarshalFrom and MarshalTo pass their
+// source and destination to the custom methods.
+func TestCustomFromTo(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c    environmentCustom
+		vars = map[string]string{"CUSTOM": "value"}
+	)
+
+	if err := UnmarshalFrom(Map(vars), &c); err != nil {
+		t.Fatal(err)
+	} else if c.Value != "value" {
+		t.Errorf("Expected `value` but returns `%s`.", c.Value)
+	}
+
+	c.Value = "new"
+	result, err := MarshalTo(Map(vars), &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(result, []string{"CUSTOM=new"}) {
+		t.Errorf("Expected [CUSTOM=new] but returns %v.", result)
+	} else if vars["CUSTOM"] != "new" {
+		t.Errorf("Expected `new` but returns `%s`.", vars["CUSTOM"])
+	}
+}
@@ -0,0 +1,95 @@
+// Package envtest provides helpers to change the environment of the
+// process in tests: the environment is restored when the test and all
+// its subtests complete.
+//
+// The environment is global for the process, so the helpers cannot be
+// used in the parallel tests: they panic if the test or its parent is
+// parallel, and the test cannot be made parallel after them.
+//
+// Example:
+//
+//    func TestConfig(t *testing.T) {
+//        envtest.Isolate(t)
+//        envtest.Load(t, "fixtures/config.env")
+//        envtest.Set(t, "PORT", "8080")
+//
+//        var config Config
+//        if err := env.Unmarshal(&config); err != nil {
+//            t.Fatal(err)
+//        }
+//        ...
+//    }
+package envtest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/goloop/env"
+)
+
+// guardKey is the variable used to deny the parallel tests.
+const guardKey = "GOLOOP_ENVTEST"
+
+// Set sets the value of the environment variable named by the key
+// for the duration of the test.
+func Set(t testing.TB, key, value string) {
+	t.Helper()
+	snapshot(t)
+
+	if err := env.Set(key, value); err != nil {
+		t.Fatalf("envtest: set %s: %v", key, err)
+	}
+}
+
+// Unset unsets the environment variable named by the key for the
+// duration of the test.
+func Unset(t testing.TB, key string) {
+	t.Helper()
+	snapshot(t)
+
+	if err := env.Unset(key); err != nil {
+		t.Fatalf("envtest: unset %s: %v", key, err)
+	}
+}
+
+// Load loads the variables from the env-file into environment for the
+// duration of the test, the existing variables are overwritten by the
+// values of the env-file (see env.Update for details).
+func Load(t testing.TB, filename string, opts ...env.Option) {
+	t.Helper()
+	snapshot(t)
+
+	if err := env.Update(filename, opts...); err != nil {
+		t.Fatalf("envtest: load %s: %v", filename, err)
+	}
+}
+
+// Isolate deletes all environment variables for the duration of
+// the test, so the test sees only the variables that it sets.
+func Isolate(t testing.TB) {
+	t.Helper()
+	snapshot(t)
+	env.Clear()
+}
+
+// snapshot saves the environment and restores it when the test
+// completes. It panics if the test is parallel.
+func snapshot(t testing.TB) {
+	t.Helper()
+
+	// The t.Setenv panics for the parallel test and denies
+	// the t.Parallel call after it.
+	value, ok := os.LookupEnv(guardKey)
+	t.Setenv(guardKey, value)
+	if !ok {
+		os.Unsetenv(guardKey)
+	}
+
+	s := env.NewSnapshot()
+	t.Cleanup(func() {
+		if err := s.Restore(); err != nil {
+			t.Errorf("envtest: restore: %v", err)
+		}
+	})
+}
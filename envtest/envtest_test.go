@@ -0,0 +1,113 @@
+package envtest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// environ returns the sorted environment.
+func environ() []string {
+	result := os.Environ()
+	sort.Strings(result)
+	return result
+}
+
+// TestSet tests Set and Unset functions.
+func TestSet(t *testing.T) {
+	os.Setenv("ENVTEST_KEY", "value")
+	defer os.Unsetenv("ENVTEST_KEY")
+	expected := environ()
+
+	t.Run("set", func(t *testing.T) {
+		Set(t, "ENVTEST_KEY", "new")
+		Set(t, "ENVTEST_NEW", "value")
+		Unset(t, "HOME")
+
+		if v := os.Getenv("ENVTEST_KEY"); v != "new" {
+			t.Errorf("Expected `new` but returns `%s`.", v)
+		}
+
+		if _, ok := os.LookupEnv("HOME"); ok {
+			t.Error("The HOME variable isn't unset.")
+		}
+	})
+
+	if result := environ(); !reflect.DeepEqual(result, expected) {
+		t.Errorf("The environment isn't restored: %v.", result)
+	}
+}
+
+// TestLoad tests Load function.
+func TestLoad(t *testing.T) {
+	os.Setenv("ENVTEST_HOST", "localhost")
+	defer os.Unsetenv("ENVTEST_HOST")
+	expected := environ()
+
+	filename := filepath.Join(t.TempDir(), "test.env")
+	data := []byte("ENVTEST_HOST=0.0.0.0\nENVTEST_PORT=8080\n")
+	if err := os.WriteFile(filename, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("load", func(t *testing.T) {
+		Load(t, filename)
+
+		host, port := os.Getenv("ENVTEST_HOST"), os.Getenv("ENVTEST_PORT")
+		if host != "0.0.0.0" || port != "8080" {
+			t.Errorf("Incorrect values: `%s`, `%s`.", host, port)
+		}
+	})
+
+	if result := environ(); !reflect.DeepEqual(result, expected) {
+		t.Errorf("The environment isn't restored: %v.", result)
+	}
+}
+
+// TestIsolate tests Isolate function.
+func TestIsolate(t *testing.T) {
+	expected := environ()
+
+	t.Run("isolate", func(t *testing.T) {
+		Isolate(t)
+		Set(t, "ENVTEST_KEY", "value")
+
+		if result := os.Environ(); len(result) != 1 {
+			t.Errorf("Expected one variable but returns %v.", result)
+		}
+	})
+
+	if result := environ(); !reflect.DeepEqual(result, expected) {
+		t.Errorf("The environment isn't restored: %v.", result)
+	}
+}
+
+// TestParallel tests that the helpers refuse to run in the parallel
+// test and the test cannot be made parallel after them.
+func TestParallel(t *testing.T) {
+	t.Run("parallel", func(t *testing.T) {
+		t.Parallel()
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic for the parallel test.")
+			}
+		}()
+		Set(t, "ENVTEST_KEY", "value")
+	})
+
+	t.Run("serial", func(t *testing.T) {
+		Set(t, "ENVTEST_KEY", "value")
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic for t.Parallel after the helper.")
+			}
+		}()
+		t.Parallel()
+	})
+
+	if _, ok := os.LookupEnv("ENVTEST_KEY"); ok {
+		t.Error("The environment isn't restored.")
+	}
+}
@@ -0,0 +1,222 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Reason is the machine-readable code of the parsing error.
+type Reason string
+
+// Reasons of the parsing errors.
+const (
+	MissingKey      Reason = "missing variable name"
+	IncorrectKey    Reason = "incorrect variable name"
+	IncorrectValue  Reason = "incorrect value"
+	UnclosedQuote   Reason = "unclosed quote"
+	UnclosedHeredoc Reason = "unclosed heredoc"
+	IncorrectEscape Reason = "incorrect escape"
+	IncludeCycle    Reason = "include cycle"
+	IncludeFailed   Reason = "unable to include"
+	Unsupported     Reason = "unsupported syntax"
+	TooLong         Reason = "expression too long"
+	DuplicateKey    Reason = "duplicate key"
+)
+
+// ParseError describes a problem with an expression of the env-file.
+//
+// Usage:
+//    var pe *env.ParseError
+//    if errors.As(err, &pe) {
+//        fmt.Println(pe.Filename, pe.Line, pe.Column, pe.Reason)
+//    }
+type ParseError struct {
+	Filename string // name of the env-file (empty for io.Reader)
+	Line     int    // 1-based line number of the problem
+	Column   int    // 1-based column (in bytes) of the problem
+	Text     string // offending text
+	Reason   Reason // reason code
+	Err      error  // underlying error (can be nil)
+}
+
+// Error returns the description of the error as:
+// filename:line:column: reason: text[: underlying error].
+func (e *ParseError) Error() string {
+	pos := fmt.Sprintf("%d:%d", e.Line, e.Column)
+	if len(e.Filename) != 0 {
+		pos = fmt.Sprintf("%s:%s", e.Filename, pos)
+	}
+
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %s: %v", pos, e.Reason, e.Text, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", pos, e.Reason, e.Text)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// newParseError returns a new ParseError for the problem in the exp
+// expression, where offset is a byte position of the problem. The line
+// and column are counted from the beginning of the exp.
+func newParseError(reason Reason, exp string, offset int) *ParseError {
+	var (
+		line   = strings.Count(exp[:offset], "\n")
+		column = offset - strings.LastIndex(exp[:offset], "\n")
+		text   = exp[offset:]
+	)
+
+	// The offending text up to the end of the line.
+	if i := strings.Index(text, "\n"); i >= 0 {
+		text = text[:i]
+	}
+
+	return &ParseError{
+		Line:   line + 1,
+		Column: column,
+		Text:   text,
+		Reason: reason,
+	}
+}
+
+// ParseErrors is a list of the problems with the expressions of the
+// env-file. It is returned when the env-file is loaded in lenient mode.
+//
+// The ParseErrors is compatible with errors.Is and errors.As (like the
+// result of the errors.Join), so:
+//
+//    var pe *env.ParseError
+//    if errors.As(err, &pe) {
+//        // pe is the first problem of the env-file
+//    }
+type ParseErrors []*ParseError
+
+// Error returns descriptions of all problems separated by a newline.
+func (e ParseErrors) Error() string {
+	var tmp = make([]string, 0, len(e))
+	for _, pe := range e {
+		tmp = append(tmp, pe.Error())
+	}
+
+	return strings.Join(tmp, "\n")
+}
+
+// Unwrap returns the list of problems as errors.
+func (e ParseErrors) Unwrap() []error {
+	var tmp = make([]error, 0, len(e))
+	for _, pe := range e {
+		tmp = append(tmp, pe)
+	}
+
+	return tmp
+}
+
+// UndefinedVar is a reference to the undefined variable.
+type UndefinedVar struct {
+	Name     string // variable name
+	Filename string // name of the env-file (empty for strings)
+	Line     int    // 1-based line number of the reference
+}
+
+// UndefinedError is returned in the strict expansion mode if the value
+// contains references to the undefined variables. It lists all such
+// references.
+type UndefinedError struct {
+	Vars []UndefinedVar
+}
+
+// Error returns the description of the error as:
+// undefined variables: NAME (filename:line), ...
+func (e *UndefinedError) Error() string {
+	var tmp = make([]string, 0, len(e.Vars))
+	for _, v := range e.Vars {
+		pos := fmt.Sprintf("line %d", v.Line)
+		if len(v.Filename) != 0 {
+			pos = fmt.Sprintf("%s:%d", v.Filename, v.Line)
+		}
+		tmp = append(tmp, fmt.Sprintf("%s (%s)", v.Name, pos))
+	}
+
+	return "undefined variables: " + strings.Join(tmp, ", ")
+}
+
+// MissingField is the required field of the structure whose variable
+// isn't set and has no default value.
+type MissingField struct {
+	Field string // path of the field, like Config.DB.Host
+	Key   string // variable name, like DB_HOST
+}
+
+// MissingError is returned by Unmarshal if the variables of the required
+// fields aren't set. It lists all such fields.
+//
+// Usage:
+//    var me *env.MissingError
+//    if errors.As(err, &me) {
+//        for _, f := range me.Fields {
+//            fmt.Println(f.Field, f.Key)
+//        }
+//    }
+type MissingError struct {
+	Fields []MissingField
+}
+
+// Error returns the description of the error as:
+// missing required variables: Config.DB.Host (DB_HOST), ...
+func (e *MissingError) Error() string {
+	var tmp = make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		tmp = append(tmp, fmt.Sprintf("%s (%s)", f.Field, f.Key))
+	}
+
+	return "missing required variables: " + strings.Join(tmp, ", ")
+}
+
+// ValidationError describes the value of the variable that violates
+// the constraint of the field's tag, like: `env:"PORT,,,min=1"`.
+type ValidationError struct {
+	Field      string // path of the field, like Config.Port
+	Key        string // variable name, like PORT
+	Value      string // raw value of the variable
+	Constraint string // violated constraint, like min=1
+}
+
+// Error returns the description of the error as:
+// Config.Port (PORT): value "0" violates min=1.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s (%s): value %q violates %s",
+		e.Field, e.Key, e.Value, e.Constraint)
+}
+
+// ValidationErrors is returned by Unmarshal if the values of the fields
+// violate the constraints. It lists all such values.
+//
+// Usage:
+//    var ve *env.ValidationError
+//    if errors.As(err, &ve) {
+//        // ve is the first violation
+//    }
+type ValidationErrors []*ValidationError
+
+// Error returns descriptions of all violations separated by a newline.
+func (e ValidationErrors) Error() string {
+	var tmp = make([]string, 0, len(e))
+	for _, ve := range e {
+		tmp = append(tmp, ve.Error())
+	}
+
+	return strings.Join(tmp, "\n")
+}
+
+// Unwrap returns the list of violations as errors.
+func (e ValidationErrors) Unwrap() []error {
+	var tmp = make([]error, 0, len(e))
+	for _, ve := range e {
+		tmp = append(tmp, ve)
+	}
+
+	return tmp
+}
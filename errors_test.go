@@ -0,0 +1,75 @@
+package env
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestParseErrorError tests Error method of the ParseError.
+func TestParseErrorError(t *testing.T) {
+	var tests = map[string]*ParseError{
+		"3:7: incorrect value: x": {
+			Line: 3, Column: 7, Text: "x", Reason: IncorrectValue,
+		},
+		".env:1:1: missing variable name: =x": {
+			Filename: ".env", Line: 1, Column: 1, Text: "=x",
+			Reason: MissingKey,
+		},
+	}
+
+	for result, pe := range tests {
+		if v := pe.Error(); v != result {
+			t.Errorf("Expected `%s` but returns `%s`.", result, v)
+		}
+	}
+}
+
+// TestParseErrorPosition tests position of the problem in the ParseError.
+func TestParseErrorPosition(t *testing.T) {
+	type sample struct {
+		data   string
+		line   int
+		column int
+		text   string
+		reason Reason
+	}
+
+	var tests = []sample{
+		{"KEY_0=0\n  KEY 1=1\n", 2, 3, "KEY 1=1", MissingKey},
+		{"KEY_0=0\nKEY_1= 1\n", 2, 7, " 1", IncorrectValue},
+		{"KEY_0='0' 1\n", 1, 11, "1", IncorrectValue},
+		{"\n\nKEY_0=\"0\nKEY_1=1\n", 3, 7, "\"0", UnclosedQuote},
+		{"KEY_0=\"0\n1\\u00\"\n", 2, 2, "\\u00\"", IncorrectEscape},
+	}
+
+	for _, test := range tests {
+		var pe *ParseError
+
+		_, err := Parse(strings.NewReader(test.data))
+		if !errors.As(err, &pe) {
+			t.Errorf("For `%s` must be a ParseError: %v", test.data, err)
+			continue
+		}
+
+		if pe.Line != test.line || pe.Column != test.column ||
+			pe.Text != test.text || pe.Reason != test.reason {
+			t.Errorf("For `%s` incorrect error: %v", test.data, pe)
+		}
+	}
+}
+
+// TestParseErrorFilename tests that the ParseError contains
+// name of the env-file.
+func TestParseErrorFilename(t *testing.T) {
+	var pe *ParseError
+
+	err := ReadParseStore("./fixtures/wrongequalkey.env", false, false, false)
+	if !errors.As(err, &pe) {
+		t.Fatalf("Must be a ParseError: %v", err)
+	}
+
+	if pe.Filename != "./fixtures/wrongequalkey.env" || pe.Line != 3 {
+		t.Errorf("Incorrect error: %v", pe)
+	}
+}
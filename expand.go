@@ -0,0 +1,302 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// The parameter expansion operators, the operators with `:` sign
+// check that the variable is set and not empty.
+var expandOperators = []string{":-", ":=", ":?", ":+", "-", "=", "?", "+"}
+
+// expander replaces ${var} or $var in the strings and supports the POSIX
+// parameter expansion operators:
+//
+//    ${var:-word}  word if var is unset or empty, otherwise value of var;
+//    ${var-word}   word if var is unset, otherwise value of var;
+//    ${var:=word}  as `:-` but sets var to the word;
+//    ${var=word}   as `-` but sets var to the word;
+//    ${var:?msg}   error with msg if var is unset or empty;
+//    ${var?msg}    error with msg if var is unset;
+//    ${var:+word}  word if var is set and not empty, otherwise empty string;
+//    ${var+word}   word if var is set, otherwise empty string.
+//
+// The word can contain variables too. The `$$` (or `\$`) is a literal
+// dollar sign.
+//
+// In strict mode the references to the undefined variables (as $var
+// or ${var}) are collected into the undefined list. In lenient mode
+// the failing reference is replaced by the empty string (or kept as is
+// if it has incorrect syntax) instead of the error.
+type expander struct {
+	lookup func(key string) (string, bool) // nil - don't replace variables
+	set    func(key, value string) error   // nil - don't set variables
+
+	strict    bool           // collect undefined variables
+	lenient   bool           // replace failing references, no errors
+	undefined []UndefinedVar // references to the undefined variables
+	line      int            // line of the current reference
+	depth     int            // nesting level of the word expansion
+}
+
+// newExpander returns expander for the current environment.
+func newExpander() *expander {
+	return &expander{lookup: os.LookupEnv, set: Set}
+}
+
+// expand replaces variables in the str. If escapes is true interprets
+// the escape sequences too (i.e. the str is a double-quoted value),
+// the escaped characters are never replaced.
+func (x *expander) expand(str string, escapes bool) (string, error) {
+	var (
+		result strings.Builder
+		line   = 1
+	)
+
+	for i := 0; i < len(str); i++ {
+		switch {
+		case str[i] == '\\' && escapes:
+			tmp, n, ok := decodeEscape(str[i:])
+			if !ok {
+				return "", fmt.Errorf("%s: %s", IncorrectEscape, str[i:])
+			}
+			result.WriteString(tmp)
+			line += strings.Count(str[i:i+n], "\n")
+			i += n - 1
+		case str[i] == '\\' && x.lookup != nil &&
+			strings.HasPrefix(str[i+1:], "$"):
+			result.WriteByte('$') // the \$ is a literal dollar sign
+			i++
+		case str[i] == '$' && x.lookup != nil && i+1 < len(str):
+			if x.depth == 0 {
+				x.line = line
+			}
+
+			tmp, n, err := x.variable(str[i+1:], escapes)
+			switch {
+			case err == nil:
+			case !x.lenient:
+				return "", err
+			case n == 0:
+				tmp = "$" // incorrect syntax is kept as is
+			default:
+				tmp = ""
+			}
+			result.WriteString(tmp)
+			line += strings.Count(str[i+1:i+1+n], "\n")
+			i += n
+		default:
+			if str[i] == '\n' {
+				line++
+			}
+			result.WriteByte(str[i])
+		}
+	}
+
+	return result.String(), nil
+}
+
+// word expands the word of the parameter expansion operator.
+func (x *expander) word(str string, escapes bool) (string, error) {
+	x.depth++
+	defer func() { x.depth-- }()
+
+	return x.expand(str, escapes)
+}
+
+// reference returns value of the variable by name and adds it
+// to the undefined list in strict mode if variable isn't set.
+func (x *expander) reference(name string) string {
+	value, ok := x.lookup(name)
+	if !ok && x.strict {
+		x.undefined = append(x.undefined,
+			UndefinedVar{Name: name, Line: x.line})
+	}
+
+	return value
+}
+
+// variable returns value of the variable at the beginning of the str
+// (the str is a text after the `$` sign) and the length of the variable
+// reference. The length is returned with the error too, it's zero if
+// the end of the reference is unknown.
+func (x *expander) variable(str string, escapes bool) (string, int, error) {
+	// The $$ is a literal dollar sign.
+	if str[0] == '$' {
+		return "$", 1, nil
+	}
+
+	// The $var form.
+	if str[0] != '{' {
+		n := nameLength(str)
+		if n == 0 {
+			return "$", 0, nil // not a variable
+		}
+
+		return x.reference(str[:n]), n, nil
+	}
+
+	// The ${var} form.
+	end := closingBrace(str, escapes)
+	if end < 0 {
+		return "", 0, fmt.Errorf("missing closing brace: $%s", str)
+	}
+
+	exp := str[1:end]
+	n := nameLength(exp)
+	if n == 0 {
+		return "", end + 1, fmt.Errorf("bad substitution: ${%s}", exp)
+	}
+
+	name, op, word := exp[:n], "", exp[n:]
+	for _, item := range expandOperators {
+		if strings.HasPrefix(word, item) {
+			op, word = item, word[len(item):]
+			break
+		}
+	}
+
+	if op == "" && word != "" {
+		return "", end + 1, fmt.Errorf("bad substitution: ${%s}", exp)
+	} else if op == "" {
+		return x.reference(name), end + 1, nil
+	}
+
+	value, ok := x.lookup(name)
+	if strings.HasPrefix(op, ":") {
+		ok = ok && value != "" // `:` means set and not empty
+	}
+
+	switch op {
+	case ":-", "-":
+		if !ok {
+			value, err := x.word(word, escapes)
+			return value, end + 1, err
+		}
+	case ":=", "=":
+		if !ok {
+			value, err := x.word(word, escapes)
+			if err == nil && x.set != nil {
+				err = x.set(name, value)
+			}
+			return value, end + 1, err
+		}
+	case ":?", "?":
+		if !ok {
+			msg, err := x.word(word, escapes)
+			if err != nil {
+				return "", end + 1, err
+			}
+
+			if msg == "" && op == ":?" {
+				msg = "parameter null or not set"
+			} else if msg == "" {
+				msg = "parameter not set"
+			}
+
+			return "", end + 1, fmt.Errorf("%s: %s", name, msg)
+		}
+	case ":+", "+":
+		if ok {
+			value, err := x.word(word, escapes)
+			return value, end + 1, err
+		}
+		return "", end + 1, nil
+	}
+
+	return value, end + 1, nil
+}
+
+// nameLength returns length of the variable name
+// at the beginning of the str.
+func nameLength(str string) int {
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		if c != '_' && (c < '0' || c > '9') &&
+			(c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			return i
+		}
+	}
+
+	return len(str)
+}
+
+// closingBrace returns index of the brace that closes the brace at
+// the beginning of the str or -1 if the closing brace is missing.
+// Nested braces are taken into account. If escapes is true the
+// escaped characters are skipped.
+func closingBrace(str string, escapes bool) int {
+	depth := 0
+	for i := 0; i < len(str); i++ {
+		switch {
+		case str[i] == '\\' && escapes:
+			i++ // skip escaped character
+		case str[i] == '{':
+			depth++
+		case str[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// Interpolate replaces ${var} or $var in the string according to the
+// values of the current environment variables and supports the POSIX
+// parameter expansion operators:
+//
+//    ${var:-word}  word if var is unset or empty, otherwise value of var;
+//    ${var-word}   word if var is unset, otherwise value of var;
+//    ${var:=word}  as `:-` but sets var to the word in the environment;
+//    ${var=word}   as `-` but sets var to the word in the environment;
+//    ${var:?msg}   returns an error with msg if var is unset or empty;
+//    ${var?msg}    returns an error with msg if var is unset;
+//    ${var:+word}  word if var is set and not empty, otherwise empty;
+//    ${var+word}   word if var is set, otherwise empty.
+//
+// References to undefined variables are replaced by the empty string.
+// The `$$` or `\$` is a literal dollar sign. Returns an error for the
+// `:?` and `?` operators or incorrect syntax.
+//
+// Examples:
+//
+//    env.Set("HOST", "localhost")
+//
+//    env.Interpolate("${HOST:-0.0.0.0}:${PORT:-8080}") // localhost:8080
+//    env.Interpolate("${HOST:+https://}${HOST}")       // https://localhost
+//    env.Interpolate("${USER:?user is required}")      // error
+func Interpolate(value string) (string, error) {
+	return newExpander().expand(value, false)
+}
+
+// ExpandStrict replaces ${var} or $var in the string like Interpolate,
+// but returns the UndefinedError if the string contains references
+// to the undefined variables. The error contains all such references
+// with line numbers (counted from the beginning of the string).
+//
+// Use `$$` or `\$` to write a literal dollar sign.
+//
+// Examples:
+//
+//    env.Set("HOST", "localhost")
+//
+//    env.ExpandStrict("$HOST:${PORT:-8080}") // localhost:8080
+//    env.ExpandStrict("$$HOST costs \\$5")   // $HOST costs $5
+//    env.ExpandStrict("$DATABSE_HOST")       // UndefinedError
+func ExpandStrict(value string) (string, error) {
+	x := newExpander()
+	x.strict = true
+
+	value, err := x.expand(value, false)
+	if err != nil {
+		return "", err
+	} else if len(x.undefined) != 0 {
+		return "", &UndefinedError{Vars: x.undefined}
+	}
+
+	return value, nil
+}
@@ -0,0 +1,220 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestInterpolate tests Interpolate function.
+func TestInterpolate(t *testing.T) {
+	var tests = [][]string{
+		{"$HOST:$PORT", "localhost:"},
+		{"${HOST}_${EMPTY}_${UNSET}", "localhost__"},
+		{"${HOST:-0.0.0.0}:${PORT:-8080}", "localhost:8080"},
+		{"${EMPTY:-default}|${EMPTY-default}", "default|"},
+		{"${UNSET:-default}|${UNSET-default}", "default|default"},
+		{"${EMPTY:+alt}|${EMPTY+alt}", "|alt"},
+		{"${HOST:+https://}${HOST}", "https://localhost"},
+		{"${UNSET:+alt}|${UNSET+alt}", "|"},
+		{"${UNSET:-${HOST:-none}/${PORT:-80}}", "localhost/80"},
+		{"${HOST:?required}", "localhost"},
+		{"$ 5 and $", "$ 5 and $"},
+		{"${UNSET:-{braces}}", "{braces}"},
+	}
+
+	Clear()
+	Set("HOST", "localhost")
+	Set("EMPTY", "")
+
+	for _, test := range tests {
+		value, err := Interpolate(test[0])
+		if err != nil {
+			t.Error(err)
+		} else if value != test[1] {
+			t.Errorf("For `%s` expected `%s` but returns `%s`.",
+				test[0], test[1], value)
+		}
+	}
+}
+
+// TestInterpolateAssign tests the `:=` and `=` operators.
+func TestInterpolateAssign(t *testing.T) {
+	Clear()
+	Set("EMPTY", "")
+
+	value, err := Interpolate("${EMPTY:=a}|${EMPTY=b}|${UNSET=c}|${UNSET:=d}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value != "a|a|c|c" {
+		t.Errorf("Expected `a|a|c|c` but returns `%s`.", value)
+	}
+
+	if Get("EMPTY") != "a" || Get("UNSET") != "c" {
+		t.Error("The variables weren't set.")
+	}
+}
+
+// TestInterpolateError tests Interpolate function for the incorrect data.
+func TestInterpolateError(t *testing.T) {
+	var tests = map[string]string{
+		"${UNSET:?host is required}": "UNSET: host is required",
+		"${EMPTY:?}":                 "EMPTY: parameter null or not set",
+		"${UNSET?}":                  "UNSET: parameter not set",
+		"${HOST%%.*}":                "bad substitution",
+		"${}":                        "bad substitution",
+		"${HOST":                     "missing closing brace",
+	}
+
+	Clear()
+	Set("HOST", "localhost")
+	Set("EMPTY", "")
+
+	for test, msg := range tests {
+		_, err := Interpolate(test)
+		if err == nil {
+			t.Errorf("For `%s` value must be an error.", test)
+		} else if !strings.Contains(err.Error(), msg) {
+			t.Errorf("For `%s` expected `%s` but returns `%s`.",
+				test, msg, err)
+		}
+	}
+
+	// The Expand function replaces the failing references only.
+	Set("ZZA", "a")
+	expand := map[string]string{
+		"${UNSET:?required}":                  "",
+		"x=$ZZA y=${ZZB:?boom}":               "x=a y=",
+		"${ZZA:-${ZZB:?boom}} ${ZZB:-${ZZA}}": "a a",
+		"${ZZB:-${ZZB:?boom}}-$ZZA":           "-a",
+		"${ZZA ${1} ${ZZA}":                   "${ZZA  a",
+	}
+	for test, expected := range expand {
+		if v := Expand(test); v != expected {
+			t.Errorf("For `%s` expected `%s` but returns `%s`.",
+				test, expected, v)
+		}
+	}
+}
+
+// TestReadParseStoreExpansion tests the parameter expansion
+// operators in the env-file.
+func TestReadParseStoreExpansion(t *testing.T) {
+	var tests = map[string]string{
+		"HOST":    "0.0.0.0",
+		"PORT":    "8080",
+		"ADDR":    "0.0.0.0:8080",
+		"SCHEME":  "http",
+		"URL":     "http://0.0.0.0:8080/",
+		"GREETED": "Hello\tWorld",
+		"LITERAL": "${UNSET:-default}",
+	}
+
+	Clear()
+	err := ReadParseStore("./fixtures/expansion.env", true, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, value := range tests {
+		if v := Get(key); value != v {
+			t.Errorf("Incorrect value for `%s` key: `%s`!=`%s`", key, value, v)
+		}
+	}
+
+	// The `:?` operator with undefined variable.
+	Clear()
+	err = ReadParseStore("./fixtures/required.env", true, false, false)
+	if err == nil || !strings.Contains(err.Error(), ":2: SECRET") {
+		t.Errorf("Expected error for line 2 but returns: %v", err)
+	}
+}
+
+// TestExpandStrict tests ExpandStrict function.
+func TestExpandStrict(t *testing.T) {
+	var tests = [][]string{
+		{"$HOST:${PORT:-8080}", "localhost:8080"},
+		{"$$HOST costs \\$5", "$HOST costs $5"},
+		{"${EMPTY}${UNSET+alt}${UNSET-}", ""},
+	}
+
+	Clear()
+	Set("HOST", "localhost")
+	Set("EMPTY", "")
+
+	for _, test := range tests {
+		value, err := ExpandStrict(test[0])
+		if err != nil {
+			t.Error(err)
+		} else if value != test[1] {
+			t.Errorf("For `%s` expected `%s` but returns `%s`.",
+				test[0], test[1], value)
+		}
+	}
+}
+
+// TestExpandStrictUndefined tests ExpandStrict function
+// for the undefined variables.
+func TestExpandStrictUndefined(t *testing.T) {
+	var ue *UndefinedError
+
+	Clear()
+	Set("HOST", "localhost")
+
+	_, err := ExpandStrict("$HOST:$PORT\n${DATABSE_HOST}\n${X:-$Y}")
+	if !errors.As(err, &ue) {
+		t.Fatalf("Must be an UndefinedError: %v", err)
+	}
+
+	expected := []UndefinedVar{
+		{Name: "PORT", Line: 1},
+		{Name: "DATABSE_HOST", Line: 2},
+		{Name: "Y", Line: 3},
+	}
+	if !reflect.DeepEqual(ue.Vars, expected) {
+		t.Errorf("Expected %v but returns %v.", expected, ue.Vars)
+	}
+
+	msg := "undefined variables: PORT (line 1), DATABSE_HOST (line 2), " +
+		"Y (line 3)"
+	if err.Error() != msg {
+		t.Errorf("Expected `%s` but returns `%s`.", msg, err)
+	}
+}
+
+// TestLoadStrict tests Load function with Strict option.
+func TestLoadStrict(t *testing.T) {
+	var ue *UndefinedError
+
+	Clear()
+	err := Load("./fixtures/undefined.env", Strict())
+	if !errors.As(err, &ue) {
+		t.Fatalf("Must be an UndefinedError: %v", err)
+	}
+
+	expected := []UndefinedVar{
+		{Name: "DATABSE_HOST", Filename: "./fixtures/undefined.env", Line: 3},
+		{Name: "USER", Filename: "./fixtures/undefined.env", Line: 6},
+	}
+	if !reflect.DeepEqual(ue.Vars, expected) {
+		t.Errorf("Expected %v but returns %v.", expected, ue.Vars)
+	}
+
+	// Nothing is stored.
+	if Exists("DATABASE_HOST") {
+		t.Error("The values were stored.")
+	}
+
+	// Without strict mode.
+	Clear()
+	if err := Load("./fixtures/undefined.env"); err != nil {
+		t.Error(err)
+	}
+
+	if v := Get("PRICE"); v != "$5" {
+		t.Errorf("Expected `$5` but returns `%s`.", v)
+	}
+}
@@ -0,0 +1,144 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// flowFiles returns names of the env-files for the envName environment
+// in ascending order of precedence (the last file has the highest
+// precedence). The .env.local file is ignored for the test environment
+// to make the test results reproducible.
+func flowFiles(envName string) []string {
+	files := []string{".env"}
+	if envName != "test" {
+		files = append(files, ".env.local")
+	}
+
+	if len(envName) != 0 {
+		files = append(files, ".env."+envName, ".env."+envName+".local")
+	}
+
+	return files
+}
+
+// LoadFlow loads the env-files from the dir directory in the dotenv-flow
+// style. The files are (from the lowest to the highest precedence):
+//
+//    .env                   - default values;
+//    .env.local             - local overrides (ignored for the test env);
+//    .env.<envName>         - values of the environment;
+//    .env.<envName>.local   - local overrides of the environment.
+//
+// The missing files are skipped. The value from the file with higher
+// precedence overrides the values from the files with lower precedence,
+// but the existing variables of the environment are never overwritten.
+// The references to other variables are resolved as in Load function
+// regardless of the file in which the variable is defined. The keys
+// defined several times in one file are handled as in Load function too
+// (the first definition is used by default, see OnDuplicate).
+//
+// Returns a report as map: the key is a name of loaded variable and the
+// value is a name of the file that supplied the variable.
+//
+// Examples:
+//
+// Suppose that the some value was set into environment as:
+//
+//    $ export PORT=80
+//
+// And there are env-files:
+//
+//    # .env
+//    HOST=0.0.0.0
+//    PORT=8080
+//    DEBUG=false
+//
+//    # .env.development
+//    DEBUG=true
+//    URL=http://${HOST}:${PORT}/
+//
+// Load files for the development environment:
+//
+//    report, err := env.LoadFlow(".", "development")
+//    if err != nil {
+//        // something went wrong
+//    }
+//
+//    // Environment:
+//    // HOST=0.0.0.0           // from the .env
+//    // PORT=80                // not overwritten
+//    // DEBUG=true             // from the .env.development
+//    // URL=http://0.0.0.0:80/ // from the .env.development
+//
+//    // Report:
+//    // map[DEBUG:.env.development HOST:.env URL:.env.development]
+func LoadFlow(dir, envName string, opts ...Option) (map[string]string, error) {
+	var (
+		entries []Entry
+		skipped ParseErrors
+		files   = make(map[string]string) // the last file of the key
+		o       = newOptions(true, false, opts)
+	)
+
+	// Parse all files.
+	for _, name := range flowFiles(envName) {
+		filename := filepath.Join(dir, name)
+		if _, err := os.Stat(filename); errors.Is(err, os.ErrNotExist) {
+			continue // skip missing file
+		}
+
+		tmp, err := parseFile(filename, o)
+		if list, ok := err.(ParseErrors); ok {
+			skipped = append(skipped, list...)
+		} else if err != nil {
+			return nil, err
+		}
+
+		// The duplicates of the file are resolved as by Load.
+		mode := o.duplicates
+		if mode == DefaultDuplicates {
+			mode = FirstWins
+		}
+
+		tmp = unique(tmp, mode)
+		for _, e := range tmp {
+			files[e.Key] = name
+		}
+		entries = append(entries, tmp...)
+	}
+
+	// The definitions of the files with the higher precedence override
+	// the previous ones, so keep the last definition of each key only.
+	last := make(map[string]int, len(entries))
+	for i, e := range entries {
+		last[e.Key] = i
+	}
+
+	result := make([]Entry, 0, len(last))
+	for i, e := range entries {
+		if last[e.Key] == i {
+			result = append(result, e)
+		}
+	}
+
+	// Store values without overwriting the existing variables.
+	keys, err := store(result, o)
+	if err != nil {
+		return nil, err
+	}
+
+	report := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if name, ok := files[key]; ok {
+			report[key] = name
+		}
+	}
+
+	if len(skipped) != 0 {
+		return report, skipped
+	}
+
+	return report, nil
+}
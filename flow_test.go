@@ -0,0 +1,127 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestFlowFiles tests flowFiles function.
+func TestFlowFiles(t *testing.T) {
+	var tests = map[string][]string{
+		"": {".env", ".env.local"},
+		"development": {".env", ".env.local", ".env.development",
+			".env.development.local"},
+		"test": {".env", ".env.test", ".env.test.local"},
+	}
+
+	for envName, files := range tests {
+		if v := flowFiles(envName); !reflect.DeepEqual(v, files) {
+			t.Errorf("For `%s` expected %v but returns %v.",
+				envName, files, v)
+		}
+	}
+}
+
+// TestLoadFlow tests LoadFlow function.
+func TestLoadFlow(t *testing.T) {
+	var (
+		tests = map[string]string{
+			"HOST":      "0.0.0.0",
+			"PORT":      "80",
+			"DEBUG":     "true",
+			"LOG_LEVEL": "debug",
+			"URL":       "http://0.0.0.0:80/local/",
+		}
+		files = map[string]string{
+			"HOST":      ".env",
+			"DEBUG":     ".env.development",
+			"LOG_LEVEL": ".env.local",
+			"URL":       ".env.development.local",
+		}
+	)
+
+	Clear()
+	Set("PORT", "80")
+	report, err := LoadFlow("./fixtures/flow", "development")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, value := range tests {
+		if v := Get(key); value != v {
+			t.Errorf("Incorrect value for `%s` key: `%s`!=`%s`", key, value, v)
+		}
+	}
+
+	if !reflect.DeepEqual(report, files) {
+		t.Errorf("Expected %v report but returns %v.", files, report)
+	}
+}
+
+// TestLoadFlowTest tests LoadFlow function for the test environment,
+// the .env.local file must be ignored.
+func TestLoadFlowTest(t *testing.T) {
+	var files = map[string]string{
+		"HOST":      ".env",
+		"PORT":      ".env",
+		"DEBUG":     ".env.test",
+		"LOG_LEVEL": ".env",
+	}
+
+	Clear()
+	report, err := LoadFlow("./fixtures/flow", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(report, files) {
+		t.Errorf("Expected %v report but returns %v.", files, report)
+	}
+
+	if v := Get("LOG_LEVEL"); v != "info" {
+		t.Errorf("Expected `info` but returns `%s`.", v)
+	}
+
+	// Missing directory.
+	Clear()
+	report, err = LoadFlow("./fixtures/nonexist", "test")
+	if err != nil || len(report) != 0 {
+		t.Errorf("Expected empty report but returns %v, %v.", report, err)
+	}
+}
+
+// TestLoadFlowDuplicates tests that the duplicate keys of one file
+// are handled as by Load function.
+func TestLoadFlowDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte("A=1\nA=2\nB=1\n")
+	if err := os.WriteFile(filepath.Join(dir, ".env"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	data = []byte("B=2\nB=3\n")
+	name := filepath.Join(dir, ".env.development")
+	if err := os.WriteFile(name, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = map[DuplicateMode]string{
+		DefaultDuplicates: "1 2",
+		FirstWins:         "1 2",
+		LastWins:          "2 3",
+	}
+
+	for mode, expected := range tests {
+		Clear()
+		_, err := LoadFlow(dir, "development", OnDuplicate(mode))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v := Get("A") + " " + Get("B"); v != expected {
+			t.Errorf("%d: expected `%s` but returns `%s`.", mode, expected, v)
+		}
+	}
+}
@@ -1,12 +1,18 @@
 package env
 
-import "os"
+import (
+	"io"
+	"io/fs"
+	"os"
+)
 
 // Load to loads data from env-file into environment without replacing
 // existing values. During loading replaces ${var} or $var in the string
-// based on the data in the environment.
+// based on the data in the environment. The filename "-" means
+// standard input.
 //
-// Returns an error in case of failure.
+// Returns an error in case of failure. The opts can change the
+// loading behavior, for example Lenient option.
 //
 // Examples:
 //
@@ -48,15 +54,16 @@ import "os"
 //    // KEY_2=VALUE_002  // add new value and replaced ${LAST_ID}
 //                        // to the plain text.
 //    echo()
-func Load(filename string) error {
-	var expand, update, forced = true, false, false
-	return ReadParseStore(filename, expand, update, forced)
+func Load(filename string, opts ...Option) error {
+	var expand, update = true, false
+	return readParseStore(filename, newOptions(expand, update, opts))
 }
 
 // LoadSafe to loads data from env-file into environment without replacing
 // existing values. Ignores the replecing of a ${var} or $var in a string.
 //
-// Returns an error in case of failure.
+// Returns an error in case of failure. The opts can change the
+// loading behavior, for example Lenient option.
 //
 // Examples:
 //
@@ -97,16 +104,17 @@ func Load(filename string) error {
 //    // KEY_1=VALUE_001         // add new value;
 //    // KEY_2=VALUE_${LAST_ID}  // add new value without replecing $var.
 //    echo()
-func LoadSafe(filename string) error {
-	var expand, update, forced = false, false, false
-	return ReadParseStore(filename, expand, update, forced)
+func LoadSafe(filename string, opts ...Option) error {
+	var expand, update = false, false
+	return readParseStore(filename, newOptions(expand, update, opts))
 }
 
 // Update to loads data from env-file into environment with replacing
 // existing values. During loading replaces ${var} or $var in the string
 // based on the data in the environment.
 //
-// Returns an error in case of failure.
+// Returns an error in case of failure. The opts can change the
+// loading behavior, for example Lenient option.
 //
 // Examples:
 //
@@ -148,15 +156,16 @@ func LoadSafe(filename string) error {
 //    // KEY_2=VALUE_002  // add new value and replaced ${LAST_ID}
 //                        // to the plain text.
 //    echo()
-func Update(filename string) error {
-	var expand, update, forced = true, true, false
-	return ReadParseStore(filename, expand, update, forced)
+func Update(filename string, opts ...Option) error {
+	var expand, update = true, true
+	return readParseStore(filename, newOptions(expand, update, opts))
 }
 
 // UpdateSafe to loads data from env-file into environment with replacing
 // existing values. Ignores the replecing of a ${var} or $var in a string.
 //
-// Returns an error in case of failure.
+// Returns an error in case of failure. The opts can change the
+// loading behavior, for example Lenient option.
 //
 // Examples:
 //
@@ -197,9 +206,133 @@ func Update(filename string) error {
 //    // KEY_1=VALUE_001         // add new value;
 //    // KEY_2=VALUE_${LAST_ID}  // add new value without replecing $var.
 //    echo()
-func UpdateSafe(filename string) error {
-	var expand, update, forced = false, true, false
-	return ReadParseStore(filename, expand, update, forced)
+func UpdateSafe(filename string, opts ...Option) error {
+	var expand, update = false, true
+	return readParseStore(filename, newOptions(expand, update, opts))
+}
+
+// LoadReader to loads data in env-file format from r into environment
+// without replacing existing values. During loading replaces ${var} or
+// $var in the string based on the data in the environment.
+//
+// The include directives are disabled by default, use the Includes
+// option to enable them.
+//
+// Returns an error in case of failure. See Load for details.
+//
+// Examples:
+//
+//    // Load values from standard input.
+//    err := env.LoadReader(os.Stdin)
+//    if err != nil {
+//        // something went wrong
+//    }
+func LoadReader(r io.Reader, opts ...Option) error {
+	var expand, update = true, false
+	o := newOptions(expand, update, opts)
+	entries, err := parse(r, "", o)
+	return storeParsed(entries, err, o)
+}
+
+// LoadSafeReader to loads data in env-file format from r into environment
+// without replacing existing values. Ignores the replecing of a ${var}
+// or $var in a string.
+//
+// Returns an error in case of failure. See LoadSafe for details.
+func LoadSafeReader(r io.Reader, opts ...Option) error {
+	var expand, update = false, false
+	o := newOptions(expand, update, opts)
+	entries, err := parse(r, "", o)
+	return storeParsed(entries, err, o)
+}
+
+// UpdateReader to loads data in env-file format from r into environment
+// with replacing existing values. During loading replaces ${var} or
+// $var in the string based on the data in the environment.
+//
+// Returns an error in case of failure. See Update for details.
+func UpdateReader(r io.Reader, opts ...Option) error {
+	var expand, update = true, true
+	o := newOptions(expand, update, opts)
+	entries, err := parse(r, "", o)
+	return storeParsed(entries, err, o)
+}
+
+// UpdateSafeReader to loads data in env-file format from r into
+// environment with replacing existing values. Ignores the replecing
+// of a ${var} or $var in a string.
+//
+// Returns an error in case of failure. See UpdateSafe for details.
+func UpdateSafeReader(r io.Reader, opts ...Option) error {
+	var expand, update = false, true
+	o := newOptions(expand, update, opts)
+	entries, err := parse(r, "", o)
+	return storeParsed(entries, err, o)
+}
+
+// LoadFS to loads data from env-file of the fsys file system (for example
+// embed.FS) into environment without replacing existing values. During
+// loading replaces ${var} or $var in the string based on the data in
+// the environment.
+//
+// The included env-files are read from fsys too, the relative paths
+// are resolved relative to the directory of the including file.
+//
+// Returns an error in case of failure. See Load for details.
+//
+// Examples:
+//
+//    //go:embed config/default.env
+//    var config embed.FS
+//
+//    ...
+//
+//    // Load default values.
+//    err := env.LoadFS(config, "config/default.env")
+//    if err != nil {
+//        // something went wrong
+//    }
+func LoadFS(fsys fs.FS, name string, opts ...Option) error {
+	var expand, update = true, false
+	o := newOptions(expand, update, opts)
+	entries, err := parseFS(fsys, name, o)
+	return storeParsed(entries, err, o)
+}
+
+// LoadSafeFS to loads data from env-file of the fsys file system into
+// environment without replacing existing values. Ignores the replecing
+// of a ${var} or $var in a string.
+//
+// Returns an error in case of failure. See LoadSafe for details.
+func LoadSafeFS(fsys fs.FS, name string, opts ...Option) error {
+	var expand, update = false, false
+	o := newOptions(expand, update, opts)
+	entries, err := parseFS(fsys, name, o)
+	return storeParsed(entries, err, o)
+}
+
+// UpdateFS to loads data from env-file of the fsys file system into
+// environment with replacing existing values. During loading replaces
+// ${var} or $var in the string based on the data in the environment.
+//
+// Returns an error in case of failure. See Update for details.
+func UpdateFS(fsys fs.FS, name string, opts ...Option) error {
+	var expand, update = true, true
+	o := newOptions(expand, update, opts)
+	entries, err := parseFS(fsys, name, o)
+	return storeParsed(entries, err, o)
+}
+
+// UpdateSafeFS to loads data from env-file of the fsys file system into
+// environment with replacing existing values. Ignores the replecing
+// of a ${var} or $var in a string.
+//
+// Returns an error in case of failure. See UpdateSafe for details.
+func UpdateSafeFS(fsys fs.FS, name string, opts ...Option) error {
+	var expand, update = false, true
+	o := newOptions(expand, update, opts)
+	entries, err := parseFS(fsys, name, o)
+	return storeParsed(entries, err, o)
 }
 
 // Exists returns true if all keys sets in the environment.
@@ -254,12 +387,46 @@ func Exists(keys ...string) bool {
 //
 // If the structure implements Unmarshaller interface - the custom UnmarshalENV
 // method will be called.
+// The UnmarshalENVFrom method of the UnmarshalerFrom interface is preferred,
+// it gets the source of the variables.
 //
-// Structure fields can have a `env` tag as `env:"key[,value[,sep]]"` where:
+// Structure fields can have a `env` tag as
+// `env:"key[,value[,sep[,options...]]]"` where:
 //
 //    key- matches the name of the key in the environment;
 //    value - default value;
-//    sep - optional argument, sets the separator for lists (default: space).
+//    sep - optional argument, sets the separator for lists (default: space);
+//    options - optional arguments separated by commas, they are taken
+//    as options only if all of them are known, otherwise the comma is
+//    a part of the separator (`env:"KEY,,a,b"` has the a,b separator).
+//
+// The key must be a POSIX variable name, use KeyPolicy option to change
+// the validation of the keys.
+//
+// The `required` option means that the variable must be set into
+// environment or have a default value, like: `env:"DB_HOST,,,required"`
+// (the option in place of the separator, like `env:"DB_HOST,,required"`,
+// is an error). All fields are checked and the MissingError lists all
+// missing variables with paths of the fields, like: Config.DB.Host
+// (DB_HOST).
+//
+// The options can set constraints of the value, they are checked after
+// conversion and the ValidationErrors lists all violations:
+//
+//    min=N, max=N - the value of the number, the length of the string
+//        or the number of the items of the list;
+//    len=N - the length of the string or the number of the items;
+//    oneof=a|b|c - one of the values separated by `|` sign;
+//    regex=EXP - the value matches the regular expression, the argument
+//        can be quoted by single quotes if it contains `,` symbol;
+//    nonempty - the value isn't empty;
+//    url - the absolute URL with scheme and host;
+//    hostport - the host and the port, like: localhost:8080;
+//    email - the email address, like: user@example.com.
+//
+// The constraints except nonempty are skipped for the empty value,
+// the format constraints are checked for each item of the list.
+// For example: `env:"PORT,8080,,min=1,max=65535"`.
 //
 // Suppose that the some values was set into environment as:
 //
@@ -309,8 +476,32 @@ func Exists(keys ...string) bool {
 //    config.Host         // "192.168.0.1"
 //    config.Port         // 80
 //    config.AllowedHosts // []string{"192.168.0.1"}
-func Unmarshal(obj interface{}) error {
-	return unmarshalENV(obj, "")
+func Unmarshal(obj interface{}, opts ...Option) error {
+	return unmarshalENV(obj, "", newOptions(false, false, opts))
+}
+
+// UnmarshalFrom gets variables from the src source (instead of the
+// environment) and sets them into object by pointer, it doesn't touch
+// the environment of the process. The custom UnmarshalENVFrom method of
+// the UnmarshalerFrom interface gets the src, but the custom UnmarshalENV
+// method knows nothing about it and is called as is (it reads the
+// environment of the process).
+//
+// P.s. See Unmarshal for details.
+//
+// Example:
+//
+//    var config Config
+//    src := env.Map(map[string]string{"HOST": "0.0.0.0", "PORT": "80"})
+//    err := env.UnmarshalFrom(src, &config)
+//    if err != nil {
+//        // something went wrong
+//    }
+func UnmarshalFrom(src Lookuper, obj interface{}, opts ...Option) error {
+	o := newOptions(false, false, opts)
+	o.src = src
+
+	return unmarshalENV(obj, "", o)
 }
 
 // Marshal converts the structure in to key/value and put it into environment
@@ -327,12 +518,19 @@ func Unmarshal(obj interface{}) error {
 //
 // If the structure implements Marshaller interface - the custom MarshalENV
 // method - will be called.
+// The MarshalENVTo method of the MarshalerTo interface is preferred,
+// it gets the destination of the variables.
 //
-// Structure fields can have a `env` tag as `env:"key[,value[,sep]]"` where:
+// Structure fields can have a `env` tag as
+// `env:"key[,value[,sep[,options...]]]"` where:
 //
 //    key- matches the name of the key in the environment;
 //    value - default value;
-//    sep - optional argument, sets the separator for lists (default: space).
+//    sep - optional argument, sets the separator for lists (default: space);
+//    options - optional arguments of the Unmarshal, they are ignored.
+//
+// The key must be a POSIX variable name, use KeyPolicy option to change
+// the validation of the keys.
 //
 // Structure example:
 //
@@ -380,6 +578,35 @@ func Unmarshal(obj interface{}) error {
 //    env.Get("HOST")          // "192.168.0.1"
 //    env.Get("PORT")          // "80"
 //    env.Get("ALLOWED_HOSTS") // "192.168.0.1"
-func Marshal(scope interface{}) ([]string, error) {
-	return marshalENV(scope, "")
+func Marshal(scope interface{}, opts ...Option) ([]string, error) {
+	return marshalENV(scope, "", newOptions(false, false, opts))
+}
+
+// MarshalTo converts the structure in to key/value and put it into the
+// dst destination (instead of the environment), it doesn't touch the
+// environment of the process. The custom MarshalENVTo method of the
+// MarshalerTo interface gets the dst, but the custom MarshalENV method
+// knows nothing about it and is called as is (it writes the environment
+// of the process).
+//
+// P.s. See Marshal for details.
+//
+// Example:
+//
+//    vars := make(map[string]string)
+//    _, err := env.MarshalTo(env.Map(vars), config)
+//    if err != nil {
+//        // something went wrong
+//    }
+//
+//    cmd := exec.Command("app")
+//    for key, value := range vars {
+//        cmd.Env = append(cmd.Env, key+"="+value)
+//    }
+func MarshalTo(dst Environment, scope interface{},
+	opts ...Option) ([]string, error) {
+	o := newOptions(false, false, opts)
+	o.dst = dst
+
+	return marshalENV(scope, "", o)
 }
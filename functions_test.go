@@ -1,7 +1,13 @@
 package env
 
 import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 // TestLoad tests Load function.
@@ -140,3 +146,172 @@ func TestExist(t *testing.T) {
 		t.Error("Expected value `false` != `true`.")
 	}
 }
+
+// TestLoadLenient tests Load function with Lenient option.
+// All correct entries must be loaded and all wrong entries reported.
+func TestLoadLenient(t *testing.T) {
+	var (
+		list  ParseErrors
+		lines = []int{4, 5, 8}
+		tests = map[string]string{
+			"KEY_0": "value_0",
+			"KEY_1": "value_1",
+			"KEY_4": "value_4",
+			"KEY_6": "777",
+			"KEY_7": "value_1",
+		}
+	)
+
+	Clear()
+	err := Load("./fixtures/wrongentries.env", Lenient())
+	if !errors.As(err, &list) {
+		t.Fatalf("Must be a ParseErrors: %v", err)
+	}
+
+	// Compare with sample.
+	for key, value := range tests {
+		if v := Get(key); value != v {
+			t.Errorf("Incorrect value for `%s` key: `%s`!=`%s`", key, value, v)
+		}
+	}
+
+	// All wrong lines are reported.
+	if len(list) != len(lines) {
+		t.Fatalf("Expected %d errors but returns %d.", len(lines), len(list))
+	}
+
+	for i, line := range lines {
+		if list[i].Line != line {
+			t.Errorf("Expected %d line but returns %d.", line, list[i].Line)
+		}
+	}
+
+	// Compatibility with errors.Join.
+	var pe *ParseError
+	if !errors.As(errors.Join(errors.New("ci"), err), &pe) || pe.Line != 4 {
+		t.Errorf("The joined error doesn't contain ParseError: %v", pe)
+	}
+}
+
+// TestLoadLenientCorrect tests Load function with Lenient option
+// for the correct env-file.
+func TestLoadLenientCorrect(t *testing.T) {
+	Clear()
+	if err := Load("./fixtures/variables.env", Lenient()); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestLoadReader tests LoadReader, LoadSafeReader, UpdateReader
+// and UpdateSafeReader functions.
+func TestLoadReader(t *testing.T) {
+	var tests = []struct {
+		load  func(r io.Reader, opts ...Option) error
+		key0  string
+		value string
+	}{
+		{LoadReader, "default", "default01"},
+		{LoadSafeReader, "default", "${KEY_0}01"},
+		{UpdateReader, "value_0", "value_001"},
+		{UpdateSafeReader, "value_0", "${KEY_0}01"},
+	}
+
+	data, err := os.ReadFile("./fixtures/variables.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, test := range tests {
+		Clear()
+		Set("KEY_0", "default")
+		if err := test.load(strings.NewReader(string(data))); err != nil {
+			t.Fatal(err)
+		}
+
+		if v := Get("KEY_0"); v != test.key0 {
+			t.Errorf("Test %d: expected `%s` but returns `%s`.",
+				i, test.key0, v)
+		}
+
+		if v := Get("KEY_2"); v != test.value {
+			t.Errorf("Test %d: expected `%s` but returns `%s`.",
+				i, test.value, v)
+		}
+	}
+}
+
+// TestLoadStdin tests Load function with "-" filename.
+func TestLoadStdin(t *testing.T) {
+	defer func(r io.Reader) { stdin = r }(stdin)
+	stdin = strings.NewReader("KEY_0=value_0\nKEY_1=${KEY_0}1\n")
+
+	Clear()
+	if err := Load("-"); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := Get("KEY_1"); v != "value_01" {
+		t.Errorf("Expected `value_01` but returns `%s`.", v)
+	}
+
+	// Parse errors contain the name of the file.
+	var pe *ParseError
+	stdin = strings.NewReader("KEY_0=value_0\n1KEY=value\n")
+	if err := Load("-"); !errors.As(err, &pe) || pe.Filename != "-" {
+		t.Errorf("Expected ParseError for `-` but returns %v.", err)
+	}
+}
+
+// TestLoadFS tests LoadFS, LoadSafeFS, UpdateFS and UpdateSafeFS
+// functions.
+func TestLoadFS(t *testing.T) {
+	var (
+		fsys = fstest.MapFS{
+			"config/app.env": {Data: []byte(
+				"source ./db/db.env\nKEY_0=value_0\nKEY_2=${DB_HOST}:1\n")},
+			"config/db/db.env": {Data: []byte(
+				"DB_HOST=localhost\nsource? ../local.env\n")},
+			"config/cycle.env": {Data: []byte("source cycle.env\n")},
+		}
+		tests = []struct {
+			load  func(fsys fs.FS, name string, opts ...Option) error
+			key0  string
+			value string
+		}{
+			{LoadFS, "default", "localhost:1"},
+			{LoadSafeFS, "default", "${DB_HOST}:1"},
+			{UpdateFS, "value_0", "localhost:1"},
+			{UpdateSafeFS, "value_0", "${DB_HOST}:1"},
+		}
+	)
+
+	for i, test := range tests {
+		Clear()
+		Set("KEY_0", "default")
+		if err := test.load(fsys, "config/app.env"); err != nil {
+			t.Fatal(err)
+		}
+
+		if v := Get("KEY_0"); v != test.key0 {
+			t.Errorf("Test %d: expected `%s` but returns `%s`.",
+				i, test.key0, v)
+		}
+
+		if v := Get("KEY_2"); v != test.value {
+			t.Errorf("Test %d: expected `%s` but returns `%s`.",
+				i, test.value, v)
+		}
+	}
+
+	// Include cycle.
+	var pe *ParseError
+	err := LoadFS(fsys, "config/cycle.env")
+	if !errors.As(err, &pe) || pe.Reason != IncludeCycle {
+		t.Errorf("Expected include cycle but returns %v.", err)
+	}
+
+	// Missing file.
+	if err := LoadFS(fsys, "config/nonexist.env"); err == nil {
+		t.Error("Expected error for missing file.")
+	}
+}
@@ -0,0 +1,66 @@
+package env
+
+// KeyPolicy sets the validator of the variable names: the names of the
+// Native dialect env-files, the keys of the `env` tags of the structure
+// fields (see Unmarshal and Marshal) and the keys written by Write.
+// The POSIXKeys validator is used by default.
+//
+// The lexer of the env-file takes as the key any characters up to the
+// `=` sign, except spaces, quotes and `#`, `$`, `\`, `<`, `>`, `;`, `&`,
+// `|`, `(`, `)`, `{`, `}` characters. The incorrect key is reported as
+// ParseError with IncorrectKey reason.
+//
+// Example:
+//
+//    // The env-file contains keys like: spring.datasource.url=...
+//    err := env.Load("app.env", env.KeyPolicy(env.RelaxedKeys))
+//    if err != nil {
+//        // something went wrong
+//    }
+//
+//    // Custom validator: the keys must have the APP_ prefix.
+//    err = env.Load("app.env", env.KeyPolicy(func(key string) bool {
+//        return strings.HasPrefix(key, "APP_") && env.POSIXKeys(key)
+//    }))
+func KeyPolicy(valid func(key string) bool) Option {
+	return func(o *options) {
+		o.keys = valid
+	}
+}
+
+// POSIXKeys returns true if the key is a correct POSIX variable name:
+// it consists of letters, digits and `_` sign and doesn't begin with
+// a digit.
+func POSIXKeys(key string) bool {
+	for i := 0; i < len(key); i++ {
+		if !isNameChar(key[i], i == 0) {
+			return false
+		}
+	}
+
+	return len(key) != 0
+}
+
+// RelaxedKeys returns true if the key consists of letters, digits, `_`,
+// `.` and `-` signs and begins with a letter or `_` sign, like the keys
+// of the Java properties: spring.datasource.url or log-level.
+func RelaxedKeys(key string) bool {
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if !isNameChar(c, i == 0) && (i == 0 || c != '.' && c != '-') {
+			return false
+		}
+	}
+
+	return len(key) != 0
+}
+
+// validKey returns true if the key is correct according to the key
+// policy of the options.
+func (o *options) validKey(key string) bool {
+	if o.keys == nil {
+		return POSIXKeys(key)
+	}
+
+	return o.keys(key)
+}
@@ -0,0 +1,114 @@
+package env
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestKeysValidators tests POSIXKeys and RelaxedKeys functions.
+func TestKeysValidators(t *testing.T) {
+	type sample struct {
+		posix   bool
+		relaxed bool
+	}
+
+	var tests = map[string]sample{
+		"KEY":                   {true, true},
+		"_key_0":                {true, true},
+		"spring.datasource.url": {false, true},
+		"log-level":             {false, true},
+		"0KEY":                  {false, false},
+		".KEY":                  {false, false},
+		"-KEY":                  {false, false},
+		"KEY:0":                 {false, false},
+		"":                      {false, false},
+	}
+
+	for key, test := range tests {
+		if v := POSIXKeys(key); v != test.posix {
+			t.Errorf("POSIXKeys(%q): expected %t but returns %t.",
+				key, test.posix, v)
+		}
+
+		if v := RelaxedKeys(key); v != test.relaxed {
+			t.Errorf("RelaxedKeys(%q): expected %t but returns %t.",
+				key, test.relaxed, v)
+		}
+	}
+}
+
+// TestKeyPolicy tests KeyPolicy option for the loaders.
+func TestKeyPolicy(t *testing.T) {
+	var pe *ParseError
+
+	// The POSIX keys are required by default.
+	Clear()
+	err := Load("./fixtures/relaxed.env")
+	if !errors.As(err, &pe) || pe.Reason != IncorrectKey || pe.Line != 2 {
+		t.Errorf("Expected incorrect key at line 2 but returns %v.", err)
+	}
+
+	// Relaxed keys.
+	err = Load("./fixtures/relaxed.env", KeyPolicy(RelaxedKeys))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = map[string]string{
+		"spring.datasource.url": "jdbc:postgresql://localhost/app",
+		"log-level":             "debug",
+		"APP_NAME":              "app",
+	}
+
+	for key, value := range tests {
+		if v := Get(key); v != value {
+			t.Errorf("Expected `%s` but returns `%s`.", value, v)
+		}
+	}
+
+	// Custom validator.
+	Clear()
+	upper := func(key string) bool {
+		return POSIXKeys(key) && strings.ToUpper(key) == key
+	}
+	data := "KEY_0=value_0\nkey_1=value_1\n"
+	err = LoadReader(strings.NewReader(data), KeyPolicy(upper), Lenient())
+	if !errors.As(err, &pe) || pe.Line != 2 || Get("KEY_0") != "value_0" {
+		t.Errorf("Expected incorrect key at line 2 but returns %v.", err)
+	}
+}
+
+// TestKeyPolicyTag tests KeyPolicy option for the tags
+// of the structure fields.
+func TestKeyPolicyTag(t *testing.T) {
+	type config struct {
+		URL   string `env:"spring.datasource.url"`
+		Level string `env:"log-level,info"`
+	}
+
+	Clear()
+	Set("spring.datasource.url", "jdbc:postgresql://localhost/app")
+
+	var c config
+	if err := Unmarshal(&c); err == nil {
+		t.Error("Expected error for the incorrect key.")
+	}
+
+	if err := Unmarshal(&c, KeyPolicy(RelaxedKeys)); err != nil {
+		t.Fatal(err)
+	} else if c.URL != "jdbc:postgresql://localhost/app" || c.Level != "info" {
+		t.Errorf("Incorrect values: %v.", c)
+	}
+
+	c.Level = "debug"
+	if _, err := Marshal(c); err == nil {
+		t.Error("Expected error for the incorrect key.")
+	}
+
+	if _, err := Marshal(c, KeyPolicy(RelaxedKeys)); err != nil {
+		t.Fatal(err)
+	} else if v := Get("log-level"); v != "debug" {
+		t.Errorf("Expected `debug` but returns `%s`.", v)
+	}
+}
@@ -0,0 +1,219 @@
+package env
+
+import "strings"
+
+// The lexer of the Native dialect scans the expression byte by byte
+// without regular expressions and allocations, the grammar is:
+//
+//    empty   = spaces [ "#" comment ]
+//    key     = spaces [ "export" spaces1 ] name
+//    name    = keychar { keychar }
+//    assign  = key "=" value
+//    heredoc = key "<<" delimiter spaces
+//    inherit = key spaces [ "#" comment ]
+//    unset   = spaces "unset" spaces1 name spaces [ "#" comment ]
+//    include = spaces ( "#include" | "source" | "." ) [ "?" ] spaces1
+//              path spaces [ "#" comment ]
+//
+// Where spaces are any number of the `\t`, `\n`, `\f`, `\r` and ` `
+// characters and spaces1 are at least one such character. The keychar
+// is any character except spaces, quotes and `#$\<>;&|(){}=` characters,
+// the name is validated by the key policy (see KeyPolicy).
+
+// isSpace returns true if c is a white space character.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\f' || c == '\r'
+}
+
+// skipSpaces returns position of the first non-space character of
+// the str beginning at the i position.
+func skipSpaces(str string, i int) int {
+	for i < len(str) && isSpace(str[i]) {
+		i++
+	}
+
+	return i
+}
+
+// isNameChar returns true if c can be the character of the variable
+// name, the first character of the name cannot be a digit.
+func isNameChar(c byte, first bool) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		return true
+	case c >= '0' && c <= '9':
+		return !first
+	}
+
+	return false
+}
+
+// isKeyChar returns true if c can be the character of the key.
+func isKeyChar(c byte) bool {
+	return !isSpace(c) && strings.IndexByte("\"'`#$\\<>;&|(){}=", c) < 0
+}
+
+// isEmpty returns true if string contains separators or comment only.
+func isEmpty(str string) bool {
+	i := skipSpaces(str, 0)
+	if i == len(str) {
+		return true
+	}
+
+	// The comment takes one line.
+	return str[i] == '#' && strings.IndexByte(str[i:], '\n') < 0
+}
+
+// lexKey returns the variable name at the beginning of the expression
+// (the spaces and the `export` prefix are skipped) and the position
+// after the name. Returns the empty key if there is no name.
+func lexKey(exp string) (key string, end int) {
+	i := skipSpaces(exp, 0)
+	if strings.HasPrefix(exp[i:], "export") &&
+		i+6 < len(exp) && isSpace(exp[i+6]) {
+		i = skipSpaces(exp, i+6)
+	}
+
+	if end = lexName(exp, i); end == i {
+		return "", 0
+	}
+
+	return exp[i:end], end
+}
+
+// lexName returns position of the end of the variable name beginning
+// at the i position.
+func lexName(exp string, i int) int {
+	for i < len(exp) && isKeyChar(exp[i]) {
+		i++
+	}
+
+	return i
+}
+
+// parseDirective returns the key and the action of the directive:
+// Remove for the `unset KEY` directive and Inherit for the pass-through
+// expression `[export] KEY` (without `=` sign).
+func parseDirective(exp string) (key string, action Action, ok bool) {
+	var end int
+
+	i := skipSpaces(exp, 0)
+	if strings.HasPrefix(exp[i:], "unset") &&
+		i+5 < len(exp) && isSpace(exp[i+5]) {
+		i = skipSpaces(exp, i+5)
+		end, action = lexName(exp, i), Remove
+		key = exp[i:end]
+	} else {
+		key, end = lexKey(exp)
+		action = Inherit
+	}
+
+	// Only the comment can be after the key.
+	if len(key) == 0 || !isEmpty(exp[end:]) {
+		return "", 0, false
+	}
+
+	return key, action, true
+}
+
+// lexAssign returns the key of the assignment and position of the value
+// (after the `=` sign). Returns the empty key if the exp isn't
+// an assignment.
+func lexAssign(exp string) (key string, pos int) {
+	key, end := lexKey(exp)
+	if len(key) == 0 || end == len(exp) || exp[end] != '=' {
+		return "", 0
+	}
+
+	return key, end + 1
+}
+
+// parseInclude returns path of the included env-file if the str is an
+// include directive, like:
+//
+//    source ./db.env
+//    . ./db.env
+//    #include db.env
+//
+// The optional is true for the directive with `?` sign, like:
+// `source? ./local.env`, `.? ./local.env` or `#include? local.env`.
+func parseInclude(str string) (path string, optional, ok bool) {
+	// The directive.
+	i := skipSpaces(str, 0)
+	switch {
+	case strings.HasPrefix(str[i:], "#include"):
+		i += len("#include")
+	case strings.HasPrefix(str[i:], "source"):
+		i += len("source")
+	case strings.HasPrefix(str[i:], "."):
+		i++
+	default:
+		return "", false, false
+	}
+
+	if i < len(str) && str[i] == '?' {
+		optional = true
+		i++
+	}
+
+	if i == len(str) || !isSpace(str[i]) {
+		return "", false, false
+	}
+	i = skipSpaces(str, i)
+
+	// The path: quoted or without spaces, quotes and `#` sign.
+	start := i
+	switch {
+	case i == len(str):
+		return "", false, false
+	case str[i] == '"' || str[i] == '\'':
+		end := strings.IndexByte(str[i+1:], str[i])
+		if end < 0 {
+			return "", false, false
+		}
+		path, i = str[i+1:i+1+end], i+end+2
+	default:
+		for i < len(str) && !isSpace(str[i]) &&
+			strings.IndexByte("#\"'", str[i]) < 0 {
+			i++
+		}
+		if i == start {
+			return "", false, false
+		}
+		path = str[start:i]
+	}
+
+	// Only the comment can be after the path.
+	if !isEmpty(str[i:]) {
+		return "", false, false
+	}
+
+	return path, optional, true
+}
+
+// parseHeredoc returns the key and the delimiter if the first line
+// of the exp begins the heredoc block, like: KEY<<EOF.
+func parseHeredoc(exp string) (key, delimiter string, ok bool) {
+	line := exp
+	if i := strings.IndexByte(exp, '\n'); i >= 0 {
+		line = exp[:i]
+	}
+
+	key, end := lexKey(line)
+	if len(key) == 0 || !strings.HasPrefix(line[end:], "<<") {
+		return "", "", false
+	}
+
+	// The delimiter is followed by spaces only.
+	start := end + 2
+	end = start
+	for end < len(line) && !isSpace(line[end]) {
+		end++
+	}
+
+	if end == start || skipSpaces(line, end) != len(line) {
+		return "", "", false
+	}
+
+	return key, line[start:end], true
+}
@@ -0,0 +1,445 @@
+package env
+
+import (
+	"bufio"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The regular expressions of the reference implementation of the grammar,
+// they are used to check the lexer.
+var (
+	referenceEmptyRegex = regexp.MustCompile(`^(\s*)$|^(\s*[#].*)$`)
+	referenceValueRegex = regexp.MustCompile(`^=[^\s].*`)
+	referenceKeyRegex   = regexp.MustCompile(
+		`^(?:\s*)?(?:export\s+)?(?P<key>[a-zA-Z_][a-zA-Z_0-9]*)=`,
+	)
+	referenceHeredocRegex = regexp.MustCompile(
+		`^\s*(?:export\s+)?([a-zA-Z_][a-zA-Z_0-9]*)<<(\S+)\s*$`,
+	)
+	referenceIncludeRegex = regexp.MustCompile(
+		`^\s*(?:#include|source|\.)(\?)?\s+` +
+			`("[^"]*"|'[^']*'|[^\s#"']+)\s*(?:#.*)?$`,
+	)
+)
+
+// lexerSamples are the expressions to compare the lexer with
+// the reference implementation.
+var lexerSamples = []string{
+	"", " ", "\t\n", "#", "# comment", "  # comment", "# a\nb",
+	"KEY=value", "KEY=value # comment", "KEY=a b # comment",
+	"KEY=a\tb #c", " export KEY=value", "export\tKEY=value",
+	"export=value", "exportKEY=value", "export  =value", "export",
+	"KEY=", "KEY= value", "KEY =value", "2KEY=value", "K EY=value",
+	"=value", "KEY", "KEY=\v", "KEY=value\u00a0", "KEY=\"value\"",
+	"KEY=\"value\" # comment", "KEY=\"value\" tail", "KEY='value",
+	"KEY='it''s'", "KEY=\"a\\\"b\"", "KEY=\"\\u00\"", "KEY=\"\\u0041\"",
+	"KEY=\"line\nline\"", "KEY=#value", "KEY=value#", "\nKEY=value",
+	"KEY<<EOF", "KEY<<EOF\nvalue\nEOF", "KEY<<EOF\nvalue", "KEY<<",
+	"KEY<< EOF", "KEY<<EOF  ", "export KEY<<EOF\nEOF", "KEY<<EOF tail",
+	"source ./db.env", "source? ./db.env", ". db.env # comment",
+	".? 'my file.env'", "#include \"db.env\"", "#include? db.env",
+	"#include db.env tail", "source", "source ", "sourcedb.env",
+	". \"db.env", ". db\"env", ". db#env", "#include", ".env=1",
+	"source 'a'#b", "source 'a'b", ". a # b\nc",
+}
+
+// referenceIsEmpty is the reference implementation of isEmpty.
+func referenceIsEmpty(str string) bool {
+	return referenceEmptyRegex.Match([]byte(str))
+}
+
+// referenceParseInclude is the reference implementation of parseInclude.
+func referenceParseInclude(str string) (path string, optional, ok bool) {
+	tmp := referenceIncludeRegex.FindStringSubmatch(str)
+	if len(tmp) < 3 {
+		return
+	}
+
+	path = tmp[2]
+	if path[0] == '"' || path[0] == '\'' {
+		path = path[1 : len(path)-1] // remove quotes
+	}
+
+	return path, tmp[1] == "?", true
+}
+
+// referenceParseHeredoc is the reference implementation of parseHeredoc.
+func referenceParseHeredoc(exp string) (key, delimiter string, ok bool) {
+	line := strings.SplitN(exp, "\n", 2)[0]
+	tmp := referenceHeredocRegex.FindStringSubmatch(line)
+	if len(tmp) < 3 {
+		return "", "", false
+	}
+
+	return tmp[1], tmp[2], true
+}
+
+// referenceIsOpenQuote is the reference implementation of isOpenQuote.
+func referenceIsOpenQuote(exp string) bool {
+	if _, delimiter, ok := referenceParseHeredoc(exp); ok {
+		lines := strings.Split(exp, "\n")
+		return len(lines) < 2 || lines[len(lines)-1] != delimiter
+	}
+
+	loc := referenceKeyRegex.FindStringIndex(exp)
+	if loc == nil || loc[1] >= len(exp) {
+		return false
+	}
+
+	value := exp[loc[1]:]
+	if value[0] != '"' && value[0] != '\'' {
+		return false
+	}
+
+	return closingQuote(value) < 0
+}
+
+// referenceParseExpression is the regexp-based reference implementation
+// of parseExpression.
+func referenceParseExpression(exp string) (key, value string,
+	quote Quoting, err error) {
+	if key, delimiter, ok := referenceParseHeredoc(exp); ok {
+		lines := strings.Split(exp, "\n")
+		if n := len(lines); n < 2 || lines[n-1] != delimiter {
+			offset := strings.Index(exp, "<<")
+			return "", "", 0, newParseError(UnclosedHeredoc, exp, offset)
+		}
+
+		value = strings.Join(lines[1:len(lines)-1], "\n")
+		return key, value, HeredocQuoted, nil
+	}
+
+	tmp := referenceKeyRegex.FindStringSubmatch(exp)
+	if len(tmp) < 2 {
+		offset := len(exp) - len(strings.TrimLeft(exp, " \t"))
+		err = newParseError(MissingKey, exp, offset)
+		return
+	}
+	key = tmp[1]
+
+	pos := strings.Index(exp, "=")
+	value = exp[pos:]
+	if !referenceValueRegex.Match([]byte(value)) {
+		err = newParseError(IncorrectValue, exp, pos+1)
+		return
+	}
+	value = strings.TrimSpace(value[1:])
+	pos++
+
+	switch {
+	case strings.HasPrefix(value, "'"), strings.HasPrefix(value, "\""):
+		end := closingQuote(value)
+		if end < 0 {
+			err = newParseError(UnclosedQuote, exp, pos)
+			return
+		}
+
+		tail := strings.TrimSpace(value[end+1:])
+		if len(tail) != 0 && tail[0] != '#' {
+			offset := pos + len(value) - len(tail)
+			err = newParseError(IncorrectValue, exp, offset)
+			return
+		}
+
+		quote = SingleQuoted
+		if value[0] == '"' {
+			quote = DoubleQuoted
+		}
+		value = value[1:end]
+
+		for i := 0; quote == DoubleQuoted && i < len(value); i++ {
+			if value[i] == '\\' {
+				_, n, ok := decodeEscape(value[i:])
+				if !ok {
+					err = newParseError(IncorrectEscape, exp, pos+1+i)
+					return
+				}
+				i += n - 1
+			}
+		}
+	default:
+		if strings.Contains(value, "#") {
+			chunks := strings.Split(value, "#")
+			chunks = strings.Split(chunks[0], " ")
+			value = strings.TrimSpace(chunks[0])
+		}
+	}
+
+	return
+}
+
+// TestLexer tests the lexer functions: the results must be the same
+// as the results of the reference implementation.
+func TestLexer(t *testing.T) {
+	for _, test := range lexerSamples {
+		if v, l := isEmpty(test), referenceIsEmpty(test); v != l {
+			t.Errorf("isEmpty(%q): expected %t but returns %t.", test, l, v)
+		}
+
+		if v, l := isOpenQuote(test), referenceIsOpenQuote(test); v != l {
+			t.Errorf("isOpenQuote(%q): expected %t but returns %t.",
+				test, l, v)
+		}
+
+		path, optional, ok := parseInclude(test)
+		lpath, loptional, lok := referenceParseInclude(test)
+		if path != lpath || optional != loptional || ok != lok {
+			t.Errorf("parseInclude(%q): expected %q, %t, %t "+
+				"but returns %q, %t, %t.",
+				test, lpath, loptional, lok, path, optional, ok)
+		}
+
+		key, delimiter, ok := parseHeredoc(test)
+		lkey, ldelimiter, lok := referenceParseHeredoc(test)
+		if key != lkey || delimiter != ldelimiter || ok != lok {
+			t.Errorf("parseHeredoc(%q): expected %q, %q, %t "+
+				"but returns %q, %q, %t.",
+				test, lkey, ldelimiter, lok, key, delimiter, ok)
+		}
+
+		key, value, quote, err := parseExpression(test, nil)
+		lkey, lvalue, lquote, lerr := referenceParseExpression(test)
+		if lerr != nil {
+			key, value, lkey, lvalue = "", "", "", "" // undefined
+		}
+
+		// The incorrect name is reported as IncorrectKey (see KeyPolicy).
+		pe, ok := err.(*ParseError)
+		if lpe, lok := lerr.(*ParseError); ok && lok &&
+			pe.Reason == IncorrectKey && lpe.Reason == MissingKey {
+			lpe.Reason = IncorrectKey
+		}
+		if key != lkey || value != lvalue || quote != lquote ||
+			!reflect.DeepEqual(err, lerr) {
+			t.Errorf("parseExpression(%q): expected %q, %q, %v, %v "+
+				"but returns %q, %q, %v, %v.", test,
+				lkey, lvalue, lquote, lerr, key, value, quote, err)
+		}
+	}
+}
+
+// The regular expressions of the baseline implementation of the parser
+// (before the lexer), they are kept to compare the performance.
+var (
+	baselineEmptyRegex = regexp.MustCompile(`^(\s*)$|^(\s*[#].*)$`)
+	baselineValueRegex = regexp.MustCompile(`^=[^\s].*`)
+	baselineKeyRegex   = regexp.MustCompile(
+		`^(?:\s*)?(?:export\s+)?(?P<key>[a-zA-Z_][a-zA-Z_0-9]*)=`,
+	)
+)
+
+// baselineIsEmpty returns true if string contains separators or comment only.
+func baselineIsEmpty(str string) bool {
+	return baselineEmptyRegex.Match([]byte(str))
+}
+
+// baselineRemoveInlineComment removes the comment in the string.
+// Only in strings where the value is enclosed in quotes.
+func baselineRemoveInlineComment(str, quote string) string {
+	// If the comment is in the string.
+	if strings.Contains(str, "#") {
+		chunks := strings.Split(str, "#")
+		for i := range chunks {
+			str := strings.Join(chunks[:i], "#")
+			if len(str) > 0 && strings.Count(str, quote)%2 == 0 {
+				return strings.TrimSpace(str)
+			}
+		}
+	}
+	return str
+}
+
+// baselineParseExpression breaks expression into key and value, ignore
+// comments and any spaces.
+//
+// Note: value must be an expression.
+func baselineParseExpression(exp string) (key, value string, err error) {
+	var (
+		quote  string = "\""
+		marker string = fmt.Sprintf("<::%d::>", time.Now().Unix())
+	)
+
+	// Get key.
+	// Remove `export` prefix, `=` suffix and trim spaces.
+	tmp := baselineKeyRegex.FindStringSubmatch(exp)
+	if len(tmp) < 2 {
+		err = fmt.Errorf("missing variable name")
+		return
+	}
+	key = tmp[1]
+
+	// Get value.
+	// ... the `=` sign in the string.
+	value = exp[strings.Index(exp, "="):]
+	if !baselineValueRegex.Match([]byte(value)) {
+		err = fmt.Errorf("incorrect value: %s", value)
+		return
+	}
+	value = strings.TrimSpace(value[1:])
+
+	switch {
+	case strings.HasPrefix(value, "'"):
+		quote = "'"
+		fallthrough
+	case strings.HasPrefix(value, "\""):
+		// Replace escaped quotes, remove comment in the string,
+		// check begin- and end- quotes and back escaped quotes.
+		value = strings.Replace(value, fmt.Sprintf("\\%s", quote), marker, -1)
+		value = baselineRemoveInlineComment(value, quote)
+		if strings.Count(value, quote)%2 != 0 { // begin- and end- quotes
+			err = fmt.Errorf("incorrect value: %s", value)
+			return
+		}
+		value = value[1 : len(value)-1] // remove begin- and end- quotes
+		// ... change `\"` and `\'` to `"` and `'`.
+		value = strings.Replace(value, marker, quote, -1)
+	default:
+		if strings.Contains(value, "#") {
+			// Split by sharp sign and for string without quotes -
+			// the first element has the meaning only.
+			chunks := strings.Split(value, "#")
+			chunks = strings.Split(chunks[0], " ")
+			value = strings.TrimSpace(chunks[0])
+		}
+	}
+
+	return
+}
+
+// baselineParse parses the data line by line as the baseline
+// ReadParseStore function does (in the forced mode, without storing
+// the variables). The baseline doesn't support the multi-line values,
+// each line of the value is parsed separately.
+func baselineParse(data string) (n int, err error) {
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		str := scanner.Text()
+		if baselineIsEmpty(str) {
+			continue
+		}
+
+		if _, _, err := baselineParseExpression(str); err == nil {
+			n++
+		}
+	}
+
+	return n, scanner.Err()
+}
+
+// benchmarkFiles returns the generated env-files for the benchmarks:
+// the typical one and the one with a large value that takes many lines.
+func benchmarkFiles() map[string]string {
+	var sb strings.Builder
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(&sb, "# Variable %d.\nKEY_%d=value_%d\n", i, i, i)
+		fmt.Fprintf(&sb, "export QUOTED_%d=\"value\\t%d\" # comment\n", i, i)
+	}
+	typical := sb.String()
+
+	line := strings.Repeat("x", 64)
+	value := strings.Repeat(line+"\n", 5000)
+
+	return map[string]string{
+		"typical":   typical,
+		"multiline": "CERT=\"" + value + "\"\n" + typical,
+	}
+}
+
+// benchmarkSamples are the typical lines of the env-file.
+var benchmarkSamples = []string{
+	"# Database settings.",
+	"",
+	"export DB_HOST=localhost",
+	"DB_PORT=5432 # default port",
+	"DB_PASSWORD=\"p@ss\\\"word # not a comment\"",
+	"DB_URL='postgres://${DB_HOST}:${DB_PORT}/app'",
+	"APP_NAME=application_with_a_rather_long_name_0123456789",
+}
+
+// BenchmarkLexer benchmarks the line processing of the lexer.
+func BenchmarkLexer(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, line := range benchmarkSamples {
+			if _, _, ok := parseInclude(line); ok || isEmpty(line) ||
+				isOpenQuote(line) {
+				continue
+			}
+			parseExpression(line, nil)
+		}
+	}
+}
+
+// BenchmarkLexerBaseline benchmarks the line processing of the baseline
+// implementation of the parser for the same lines as BenchmarkLexer.
+func BenchmarkLexerBaseline(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, line := range benchmarkSamples {
+			if baselineIsEmpty(line) {
+				continue
+			}
+			baselineParseExpression(line)
+		}
+	}
+}
+
+// BenchmarkParse benchmarks Parse function for the generated env-files.
+func BenchmarkParse(b *testing.B) {
+	for name, data := range benchmarkFiles() {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if _, err := Parse(strings.NewReader(data)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkParseBaseline benchmarks the baseline implementation of the
+// parser for the same env-files as BenchmarkParse.
+func BenchmarkParseBaseline(b *testing.B) {
+	for name, data := range benchmarkFiles() {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if _, err := baselineParse(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkParseMultiline benchmarks Parse function for the large values
+// that take many lines (the time must grow linearly with the size).
+func BenchmarkParseMultiline(b *testing.B) {
+	line := strings.Repeat("x", 64)
+	value := strings.Repeat(line+"\n", 40000)
+	tests := map[string]string{
+		"quoted":       "KEY=\"" + value + "\"\n",
+		"heredoc":      "KEY<<EOF\n" + value + "EOF\n",
+		"continuation": "KEY=" + strings.ReplaceAll(value, "\n", "\\\n") + "\n",
+	}
+
+	for name, data := range tests {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if _, err := Parse(strings.NewReader(data)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package env
+
+// Option configures the loading of the env-file.
+type Option func(*options)
+
+// options are settings for loading of the env-file.
+type options struct {
+	expand  bool   // replace ${var} or $var in the values
+	update  bool   // overwrite the existing variables
+	forced  bool   // ignore wrong entries silently
+	lenient bool   // ignore wrong entries and report them
+	strict  bool   // return an error for undefined variables
+	dialect Format // dialect of the env-file format
+	heredoc string // delimiter of the heredoc blocks for writing
+	maxSize int    // maximum size of the expression, 0 is unlimited
+
+	// The base directory of the includes of the data read from
+	// io.Reader or standard input, empty means disabled includes.
+	includes string
+
+	// The handling of the keys defined several times.
+	duplicates DuplicateMode
+
+	// The validator of the keys, nil means POSIXKeys.
+	keys func(key string) bool
+
+	// The source of Unmarshal and destination of Marshal,
+	// nil means the environment of the process.
+	src Lookuper
+	dst Environment
+
+	// The precedence of the file values over the environment values
+	// for references, zero means file values for the update mode and
+	// environment values otherwise.
+	precedence int
+}
+
+// Precedence of the values for references.
+const (
+	fileFirst = iota + 1
+	envFirst
+)
+
+// newOptions returns options with expand and update flags
+// modified by the opts.
+func newOptions(expand, update bool, opts []Option) *options {
+	o := &options{expand: expand, update: update}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// Lenient loads every correct entry of the env-file and ignores the
+// wrong ones, but returns the ParseErrors error with a list of all
+// skipped lines.
+//
+// Example:
+//
+//    err := env.Load(".env", env.Lenient())
+//    if err != nil {
+//        // All correct entries are loaded, but the file
+//        // contains incorrect expressions.
+//        log.Println(err)
+//    }
+func Lenient() Option {
+	return func(o *options) {
+		o.lenient = true
+	}
+}
+
+// Strict returns the UndefinedError if values of the env-file contain
+// references to the undefined variables (as $var or ${var}). Nothing is
+// stored into environment in this case. Use `$$` or `\$` to write
+// a literal dollar sign.
+//
+// Example:
+//
+//    err := env.Load(".env", env.Strict())
+//    if err != nil {
+//        // undefined variables: DATABSE_HOST (.env:3)
+//        log.Fatal(err)
+//    }
+func Strict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// FileFirst resolves references to the variables that are defined both
+// in the env-file and in the environment by the env-file values. It is
+// the default behavior for Update and UpdateSafe functions.
+//
+// Example:
+//
+//    // $ export HOST=localhost
+//    // The .env file contains:
+//    //    HOST=0.0.0.0
+//    //    ADDR=${HOST}:8080
+//
+//    env.Load(".env", env.FileFirst())
+//    env.Get("HOST") // localhost (not overwritten)
+//    env.Get("ADDR") // 0.0.0.0:8080
+func FileFirst() Option {
+	return func(o *options) {
+		o.precedence = fileFirst
+	}
+}
+
+// EnvFirst resolves references to the variables that are defined both
+// in the env-file and in the environment by the environment values.
+// It is the default behavior for Load and LoadSafe functions.
+//
+// Example:
+//
+//    // $ export HOST=localhost
+//    // The .env file contains:
+//    //    HOST=0.0.0.0
+//    //    ADDR=${HOST}:8080
+//
+//    env.Update(".env", env.EnvFirst())
+//    env.Get("HOST") // 0.0.0.0 (overwritten)
+//    env.Get("ADDR") // localhost:8080
+func EnvFirst() Option {
+	return func(o *options) {
+		o.precedence = envFirst
+	}
+}
+
+// Heredoc writes the multi-line values as heredoc blocks (like the
+// $GITHUB_ENV files of the GitHub Actions) by Write and SaveFile
+// functions. The delimiter is EOF if it's empty, the number suffix
+// is added to the delimiter if the value contains a line equal to it.
+//
+// Example:
+//
+//    err := env.SaveFile(os.Getenv("GITHUB_ENV"), map[string]string{
+//        "CHANGELOG": "- first\n- second",
+//    }, env.Heredoc(""))
+//
+//    // The file:
+//    // CHANGELOG<<EOF
+//    // - first
+//    // - second
+//    // EOF
+func Heredoc(delimiter string) Option {
+	if delimiter == "" {
+		delimiter = "EOF"
+	}
+
+	return func(o *options) {
+		o.heredoc = delimiter
+	}
+}
+
+// MaxSize limits the size of the expression of the env-file (the line
+// or several lines of the multi-line value) by n bytes, the size isn't
+// limited by default. The too long expression is reported as ParseError
+// with TooLong reason, the data of such expression isn't kept in memory.
+//
+// Example:
+//
+//    // The env-file from untrusted source.
+//    err := env.LoadReader(r, env.MaxSize(1<<20)) // 1 MiB
+//    if err != nil {
+//        // something went wrong
+//    }
+func MaxSize(n int) Option {
+	return func(o *options) {
+		o.maxSize = n
+	}
+}
+
+// Includes enables the include directives for the env-file data read
+// from io.Reader or standard input, the relative paths of the included
+// env-files are resolved relative to the dir directory (the current
+// directory if dir is empty). The include directives of such data are
+// disabled by default: the `#include` line is a comment and the other
+// directives are reported as ParseError with IncludeFailed reason.
+//
+// Example:
+//
+//    // The env-file is read from standard input, but can include
+//    // the env-files from the config directory.
+//    err := env.LoadReader(os.Stdin, env.Includes("/etc/app"))
+//    if err != nil {
+//        // something went wrong
+//    }
+func Includes(dir string) Option {
+	if dir == "" {
+		dir = "."
+	}
+
+	return func(o *options) {
+		o.includes = dir
+	}
+}
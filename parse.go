@@ -0,0 +1,402 @@
+package env
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// stdin is the source of the env-file named "-".
+var stdin io.Reader = os.Stdin
+
+// Quoting is the quoting style of the value in the env-file.
+type Quoting int
+
+// Quoting styles.
+const (
+	Unquoted       Quoting = iota // KEY=value
+	SingleQuoted                  // KEY='value'
+	DoubleQuoted                  // KEY="value"
+	BacktickQuoted                // KEY=`value` (Dotenv dialect only)
+	HeredocQuoted                 // KEY<<EOF ... EOF
+)
+
+// String returns the name of the quoting style.
+func (q Quoting) String() string {
+	switch q {
+	case Unquoted:
+		return "unquoted"
+	case SingleQuoted:
+		return "single-quoted"
+	case DoubleQuoted:
+		return "double-quoted"
+	case BacktickQuoted:
+		return "backtick-quoted"
+	case HeredocQuoted:
+		return "heredoc"
+	}
+
+	return "unknown"
+}
+
+// Action is the action of the env-file entry with the variable.
+type Action int
+
+// Actions of the entries.
+const (
+	Assign  Action = iota // KEY=value
+	Inherit               // KEY - the value is taken from the environment
+	Remove                // unset KEY - the variable is removed
+)
+
+// String returns the name of the action.
+func (a Action) String() string {
+	switch a {
+	case Assign:
+		return "assign"
+	case Inherit:
+		return "inherit"
+	case Remove:
+		return "unset"
+	}
+
+	return "unknown"
+}
+
+// Entry is a key/value pair of the env-file.
+type Entry struct {
+	Key      string  // variable name
+	Value    string  // value with interpreted escape sequences
+	Quote    Quoting // quoting style of the value
+	Action   Action  // action with the variable
+	Filename string  // name of the env-file (empty for io.Reader)
+	Line     int     // number of the line where the entry begins
+
+	raw string // value as it is written in the file (without quotes)
+}
+
+// Parse reads env-file data from r and returns the key/value pairs
+// in the order they are written. Parse doesn't change the environment
+// and doesn't replace ${var} or $var in the values.
+//
+// The include directives are disabled by default: the `#include` line
+// is a comment and the other directives are incorrect expressions. Use
+// the Includes option to enable them, the entries of the included
+// env-files (see ReadParseStore) are inserted in place of the include
+// directive.
+//
+// Returns an error for the first incorrect expression. The opts can
+// change the parsing behavior, for example Dialect or Lenient option.
+//
+// All definitions of the repeated keys are returned (see the Duplicates
+// method of the Entries), but the OnDuplicate option with
+// RejectDuplicates mode makes the repeated key an incorrect expression.
+//
+// The directives are returned as the entries with the Action: the
+// `unset KEY` directive as Remove and the pass-through `KEY` expression
+// (without `=` sign) as Inherit with the value of the environment.
+//
+// Examples:
+//
+// Suppose that there is .env file with data:
+//
+//    HOST=0.0.0.0
+//    PORT=8080 # comment
+//    GREETING='Hello, ${USER}!'
+//
+// Parse the file and print the entries:
+//
+//    file, err := os.Open(".env")
+//    if err != nil {
+//        // something went wrong
+//    }
+//    defer file.Close()
+//
+//    entries, err := env.Parse(file)
+//    if err != nil {
+//        // something went wrong
+//    }
+//
+//    for _, e := range entries {
+//        fmt.Println(e.Line, e.Key, e.Value, e.Quote)
+//    }
+//
+//    // Output:
+//    // 1 HOST 0.0.0.0 unquoted
+//    // 2 PORT 8080 unquoted
+//    // 3 GREETING Hello, ${USER}! single-quoted
+func Parse(r io.Reader, opts ...Option) (Entries, error) {
+	return parse(r, "", newOptions(false, false, opts))
+}
+
+// ParseFile reads env-file and returns the key/value pairs in the
+// order they are written. It doesn't change the environment. The paths
+// of the included env-files are resolved relative to the directory of
+// the including file.
+//
+// P.s. See Parse for details.
+func ParseFile(filename string, opts ...Option) (Entries, error) {
+	return parseFile(filename, newOptions(false, false, opts))
+}
+
+// parseFile reads env-file by name and returns the key/value pairs.
+// The filename "-" means standard input.
+func parseFile(filename string, o *options) ([]Entry, error) {
+	if filename == "-" {
+		return parse(stdin, filename, o)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err // unable to open file
+	}
+	defer file.Close()
+
+	return parse(file, filename, o)
+}
+
+// parse reads env-file data from r and returns the key/value pairs.
+// The filename is used in the parsing errors and to resolve paths of
+// the included env-files.
+//
+// Ignores wrong entries and returns all correct ones if forced or
+// lenient option is set, in the lenient mode returns ParseErrors
+// with all ignored entries too.
+func parse(r io.Reader, filename string, o *options) ([]Entry, error) {
+	return (&parser{options: o}).run(r, filename)
+}
+
+// parseFS reads env-file by name from the fsys file system and returns
+// the key/value pairs. The included env-files are read from fsys too.
+func parseFS(fsys fs.FS, name string, o *options) ([]Entry, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err // unable to open file
+	}
+	defer file.Close()
+
+	return (&parser{options: o, fsys: fsys}).run(file, name)
+}
+
+// parser parses the env-file and the env-files included into it.
+type parser struct {
+	*options
+
+	fsys    fs.FS            // file system of the env-files, nil for OS
+	stack   []string         // included files
+	skipped ParseErrors      // ignored entries
+	seen    map[string]Entry // the first definitions of the keys
+}
+
+// run parses env-file data from r and returns the key/value pairs and
+// the ignored entries (if any) as ParseErrors.
+func (p *parser) run(r io.Reader, filename string) ([]Entry, error) {
+	p.seen = make(map[string]Entry)
+	entries, err := p.parse(r, filename)
+	if err != nil {
+		return nil, err
+	} else if len(p.skipped) != 0 {
+		return entries, p.skipped
+	}
+
+	return entries, nil
+}
+
+// open opens the included env-file by name.
+func (p *parser) open(name string) (io.ReadCloser, error) {
+	if p.fsys != nil {
+		return p.fsys.Open(name)
+	}
+
+	return os.Open(name)
+}
+
+// includable returns true if the env-file by filename can include other
+// env-files: the data of io.Reader (the empty filename) and standard
+// input can include them if the Includes option is set only.
+func (p *parser) includable(filename string) bool {
+	return p.fsys != nil || len(p.includes) != 0 ||
+		filename != "" && filename != "-"
+}
+
+// join resolves the path of the included env-file relative to the
+// directory of the filename (or the directory of the Includes option
+// for io.Reader and standard input). The fs.FS uses slash-separated
+// paths.
+func (p *parser) join(filename, name string) string {
+	if p.fsys != nil {
+		if strings.HasPrefix(name, "/") {
+			return path.Clean(name[1:])
+		}
+		return path.Join(path.Dir(filename), name)
+	}
+
+	if filepath.IsAbs(name) {
+		return filepath.Clean(name)
+	} else if filename == "" || filename == "-" {
+		return filepath.Join(p.includes, name)
+	}
+
+	return filepath.Join(filepath.Dir(filename), name)
+}
+
+// skip returns true if the wrong entry must be ignored.
+func (p *parser) skip(pe *ParseError) bool {
+	if p.lenient {
+		p.skipped = append(p.skipped, pe)
+	}
+
+	return p.forced || p.lenient
+}
+
+// parse reads env-file data from r and returns the key/value pairs.
+func (p *parser) parse(r io.Reader, filename string) ([]Entry, error) {
+	var (
+		entries []Entry
+		syntax  = p.syntax()
+		reader  = newExprReader(r, syntax, p.maxSize)
+	)
+
+	reader.resync = p.forced || p.lenient
+
+	if p.fsys != nil {
+		p.stack = append(p.stack, path.Clean(filename))
+	} else {
+		p.stack = append(p.stack, filepath.Clean(filename))
+	}
+	defer func() { p.stack = p.stack[:len(p.stack)-1] }()
+
+	for {
+		// Get next expression, empty string or comments are ignored.
+		str, line, err := reader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			if reader.Err() != nil {
+				return nil, err // unable to read
+			}
+
+			pe := err.(*ParseError)
+			pe.Filename = filename
+			if p.skip(pe) {
+				continue // ignore unclosed quote
+			}
+			return nil, err
+		}
+
+		// Include directive.
+		if path, optional, ok := syntax.include(str); ok {
+			if !p.includable(filename) && isEmpty(str) {
+				continue // the `#include` line is a comment
+			}
+
+			tmp, err := p.include(path, optional, filename)
+			if err == nil {
+				entries = append(entries, tmp...)
+				continue
+			}
+
+			pe, ok := err.(*ParseError)
+			if !ok {
+				return nil, err
+			}
+
+			// The problem with the include directive (not with
+			// the included file).
+			if pe.Filename == "" {
+				pe.Filename, pe.Line = filename, line
+				pe.Column = len(str) - len(strings.TrimLeft(str, " \t")) + 1
+				pe.Text = strings.TrimSpace(str)
+				if p.skip(pe) {
+					continue // ignore wrong directive
+				}
+			}
+			return nil, err
+		}
+
+		// Parse expression.
+		// The string containing the expression must be of the
+		// format like: [export] KEY=VALUE [# Comment]
+		// (depends on the dialect).
+		e, err := syntax.parse(str)
+		if err != nil {
+			pe, ok := err.(*ParseError)
+			if !ok {
+				return nil, err
+			}
+
+			pe.Filename = filename
+			pe.Line += line - 1
+			if p.skip(pe) {
+				continue // ignore wrong entry
+			}
+			return nil, err // incorrect expression
+		} else if e.Key == "" {
+			continue // ignored by the dialect
+		}
+
+		e.Filename, e.Line = filename, line
+		if p.duplicates == RejectDuplicates && e.Action == Assign {
+			if previous, ok := p.seen[e.Key]; ok {
+				pe := duplicateError(previous, e, str)
+				if p.skip(pe) {
+					continue // ignore repeated definition
+				}
+				return nil, pe
+			}
+			p.seen[e.Key] = e
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// include parses the env-file by name, the relative path is resolved
+// relative to the directory of the filename (the including file).
+// If optional is true the missing file is ignored. Returns an error
+// if the filename cannot include other env-files.
+//
+// The problem with the include directive is returned as ParseError
+// without filename and position.
+func (p *parser) include(
+	name string,
+	optional bool,
+	filename string,
+) ([]Entry, error) {
+	if !p.includable(filename) {
+		return nil, &ParseError{
+			Reason: IncludeFailed,
+			Err:    errors.New("includes are disabled, see Includes option"),
+		}
+	}
+
+	name = p.join(filename, name)
+
+	// Cycle detection.
+	for i, item := range p.stack {
+		if item == name {
+			chain := append(append([]string{}, p.stack[i:]...), name)
+			return nil, &ParseError{
+				Reason: IncludeCycle,
+				Err:    errors.New(strings.Join(chain, " -> ")),
+			}
+		}
+	}
+
+	file, err := p.open(name)
+	if err != nil {
+		if optional && errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, &ParseError{Reason: IncludeFailed, Err: err}
+	}
+	defer file.Close()
+
+	return p.parse(file, name)
+}
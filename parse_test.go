@@ -0,0 +1,360 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestParse tests Parse function.
+func TestParse(t *testing.T) {
+	var (
+		data = "# Comment.\n" +
+			"export KEY_0=value_0 # comment\n" +
+			"\n" +
+			"KEY_1='${KEY_0}\\n'\n" +
+			"KEY_2=\"line_0\n" +
+			"line_1\\t\"\n" +
+			"KEY_0=\"${KEY_1}\"\n"
+		tests = []Entry{
+			{Key: "KEY_0", Value: "value_0", Quote: Unquoted, Line: 2},
+			{Key: "KEY_1", Value: "${KEY_0}\\n", Quote: SingleQuoted, Line: 4},
+			{Key: "KEY_2", Value: "line_0\nline_1\t", Quote: DoubleQuoted,
+				Line: 5},
+			{Key: "KEY_0", Value: "${KEY_1}", Quote: DoubleQuoted, Line: 7},
+		}
+	)
+
+	entries, err := Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != len(tests) {
+		t.Fatalf("Expected %d entries but returns %d.",
+			len(tests), len(entries))
+	}
+
+	for i, test := range tests {
+		e := entries[i]
+		if e.Key != test.Key || e.Value != test.Value ||
+			e.Quote != test.Quote || e.Line != test.Line {
+			t.Errorf("Expected %v but returns %v.", test, e)
+		}
+	}
+}
+
+// TestParseIncorrect tests Parse function for the incorrect data.
+func TestParseIncorrect(t *testing.T) {
+	var tests = []string{
+		"KEY_0=value_0\nKEY_1 = value_1\n",
+		"KEY_0=\"value_0\n",
+		"KEY_0=\"\\u00\"\n",
+	}
+
+	for _, test := range tests {
+		if _, err := Parse(strings.NewReader(test)); err == nil {
+			t.Errorf("For `%s` value must be an error.", test)
+		}
+	}
+}
+
+// TestParseFile tests ParseFile function.
+// The function must not change the environment.
+func TestParseFile(t *testing.T) {
+	var tests = []string{"KEY_0", "KEY_1", "KEY_2", "KEY_3", "KEY_4"}
+
+	Clear()
+	entries, err := ParseFile("./fixtures/variables.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != len(tests) {
+		t.Fatalf("Expected %d entries but returns %d.",
+			len(tests), len(entries))
+	}
+
+	for i, key := range tests {
+		if entries[i].Key != key {
+			t.Errorf("Expected `%s` key but returns `%s`.",
+				key, entries[i].Key)
+		}
+
+		if entries[i].Line != i+1 {
+			t.Errorf("Expected %d line but returns %d.",
+				i+1, entries[i].Line)
+		}
+	}
+
+	if len(Environ()) != 0 {
+		t.Error("The environment has been changed.")
+	}
+
+	if v := entries[2].Value; v != "${KEY_0}01" {
+		t.Errorf("Expected value `${KEY_0}01` != `%s`.", v)
+	}
+}
+
+// TestParseFileOpen tests ParseFile when try to open a nonexistent file.
+func TestParseFileOpen(t *testing.T) {
+	if _, err := ParseFile("./fixtures/nonexist.env"); err == nil {
+		t.Error("Reading from a nonexistent file.")
+	}
+}
+
+// TestParseFileInclude tests include directives.
+func TestParseFileInclude(t *testing.T) {
+	type sample struct {
+		key      string
+		value    string
+		filename string
+		line     int
+	}
+
+	var tests = []sample{
+		{"HOST", "0.0.0.0", "fixtures/include/base.env", 1},
+		{"PORT", "8080", "fixtures/include/base.env", 2},
+		{"DB_HOST", "localhost", "fixtures/include/db/db.env", 1},
+		{"HOST", "0.0.0.0", "fixtures/include/base.env", 1},
+		{"PORT", "8080", "fixtures/include/base.env", 2},
+		{"DB_URL", "postgres://${DB_HOST}/", "fixtures/include/db/db.env", 3},
+		{"PORT", "8000", "fixtures/include/local.env", 1},
+		{"APP", "main", "./fixtures/include/main.env", 6},
+	}
+
+	entries, err := ParseFile("./fixtures/include/main.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != len(tests) {
+		t.Fatalf("Expected %d entries but returns %d.",
+			len(tests), len(entries))
+	}
+
+	for i, test := range tests {
+		e := entries[i]
+		if e.Key != test.key || e.Value != test.value ||
+			e.Filename != test.filename || e.Line != test.line {
+			t.Errorf("Expected %v but returns %v.", test, e)
+		}
+	}
+}
+
+// TestParseFileIncludeError tests include cycles and missing files.
+func TestParseFileIncludeError(t *testing.T) {
+	var pe *ParseError
+
+	// Include cycle.
+	_, err := ParseFile("./fixtures/include/cycle_a.env")
+	if !errors.As(err, &pe) || pe.Reason != IncludeCycle {
+		t.Fatalf("Must be an include cycle: %v", err)
+	}
+
+	msg := "fixtures/include/cycle_b.env:2:1: include cycle: " +
+		"#include cycle_a.env: fixtures/include/cycle_a.env -> " +
+		"fixtures/include/cycle_b.env -> fixtures/include/cycle_a.env"
+	if err.Error() != msg {
+		t.Errorf("Expected `%s` but returns `%s`.", msg, err)
+	}
+
+	// Missing file.
+	_, err = ParseFile("./fixtures/include/missing.env")
+	if !errors.As(err, &pe) || pe.Reason != IncludeFailed || pe.Line != 2 {
+		t.Fatalf("Must be an include error: %v", err)
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Must be a not exist error: %v", err)
+	}
+}
+
+// TestParseContinuation tests the line continuation.
+func TestParseContinuation(t *testing.T) {
+	var (
+		data = "KEY_0=first\\\n" +
+			"second\\\n" +
+			"  third\n" +
+			"KEY_1=C:\\\\\n" +
+			"KEY_2=\"quoted\\\n" +
+			"value\"\n" +
+			"KEY_3=last\\"
+		tests = []Entry{
+			{Key: "KEY_0", Value: "firstsecond  third", Line: 1},
+			{Key: "KEY_1", Value: "C:\\\\", Line: 4},
+			{Key: "KEY_2", Value: "quoted\\\nvalue", Line: 5},
+			{Key: "KEY_3", Value: "last\\", Line: 7},
+		}
+	)
+
+	entries, err := Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != len(tests) {
+		t.Fatalf("Expected %d entries but returns %d.",
+			len(tests), len(entries))
+	}
+
+	for i, test := range tests {
+		if e := entries[i]; e.Key != test.Key || e.Value != test.Value ||
+			e.Line != test.Line {
+			t.Errorf("Expected %v but returns %v.", test, e)
+		}
+	}
+}
+
+// TestParseLongLine tests Parse function for the lines
+// longer than the buffer of the reader.
+func TestParseLongLine(t *testing.T) {
+	value := strings.Repeat("QUJDREVGR0hJSktMTU5PUFFSU1RVVldYWVo=", 6000)
+	data := "KEY_0=" + value + "\nKEY_1=\"" + value + "\n" + value + "\"\n"
+
+	entries, err := Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 2 || entries[0].Value != value ||
+		entries[1].Value != value+"\n"+value || entries[1].Line != 2 {
+		t.Error("Incorrect value of the long line.")
+	}
+}
+
+// TestParseMaxSize tests MaxSize option.
+func TestParseMaxSize(t *testing.T) {
+	value := strings.Repeat("x", 1024)
+	tests := map[string]int{
+		"KEY_0=value\nKEY_1=" + value + "\nKEY_2=value\n":        2,
+		"KEY_0=value\nKEY_1=\"x\n" + value + "\"\nKEY_2=value\n": 2,
+		"KEY_0=value\nKEY_1=x\\\n" + value + "\nKEY_2=value\n":   2,
+	}
+
+	for data, line := range tests {
+		var pe *ParseError
+		_, err := Parse(strings.NewReader(data), MaxSize(1000))
+		if !errors.As(err, &pe) || pe.Reason != TooLong || pe.Line != line {
+			t.Errorf("Expected too long expression at line %d "+
+				"but returns %v.", line, err)
+		}
+
+		// The too long expression is skipped in lenient mode.
+		entries, err := Parse(strings.NewReader(data), MaxSize(1000),
+			Lenient())
+		if err == nil || len(entries) != 2 || entries[1].Key != "KEY_2" {
+			t.Errorf("Expected KEY_0 and KEY_2 but returns %v.", entries)
+		}
+	}
+
+	data := "KEY=" + value[4:]
+	if _, err := Parse(strings.NewReader(data), MaxSize(1024)); err != nil {
+		t.Errorf("Expected nil but returns %v.", err)
+	}
+}
+
+// TestParseDirectives tests Parse function for the pass-through
+// and unset directives.
+func TestParseDirectives(t *testing.T) {
+	var (
+		data  = "KEY_0\n  export KEY_1 # comment\nunset KEY_2\nunset\n"
+		tests = []Entry{
+			{Key: "KEY_0", Value: "env", Action: Inherit, Line: 1},
+			{Key: "KEY_1", Action: Inherit, Line: 2},
+			{Key: "KEY_2", Action: Remove, Line: 3},
+			{Key: "unset", Action: Inherit, Line: 4},
+		}
+	)
+
+	Clear()
+	Set("KEY_0", "env")
+	entries, err := Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != len(tests) {
+		t.Fatalf("Expected %d entries but returns %d.",
+			len(tests), len(entries))
+	}
+
+	for i, test := range tests {
+		if e := entries[i]; e.Key != test.Key || e.Value != test.Value ||
+			e.Action != test.Action || e.Line != test.Line {
+			t.Errorf("Expected %v but returns %v.", test, e)
+		}
+	}
+
+	// Incorrect directives.
+	for _, test := range []string{"unset 1KEY", "unset KEY tail", "KEY tail"} {
+		if _, err := Parse(strings.NewReader(test)); err == nil {
+			t.Errorf("For `%s` value must be an error.", test)
+		}
+	}
+}
+
+// TestParseLenientUnclosed tests that the lines after the unclosed
+// quote are parsed in the lenient and forced modes.
+func TestParseLenientUnclosed(t *testing.T) {
+	data := "A='x\nB=1\nC=\"y\nD=2\n"
+	for _, opt := range []Option{Lenient(), func(o *options) {
+		o.forced = true
+	}} {
+		entries, err := Parse(strings.NewReader(data), opt)
+		if len(entries) != 2 || entries[0].Key != "B" ||
+			entries[0].Line != 2 || entries[1].Key != "D" ||
+			entries[1].Line != 4 {
+			t.Errorf("Expected B and D entries but returns %v.", entries)
+		}
+
+		var list ParseErrors
+		if err == nil {
+			continue // forced mode
+		} else if !errors.As(err, &list) || len(list) != 2 ||
+			list[0].Line != 1 || list[1].Line != 3 ||
+			list[0].Reason != UnclosedQuote {
+			t.Errorf("Expected errors at lines 1 and 3 but returns %v.", err)
+		}
+	}
+
+	// The unclosed quote is an error in the default mode.
+	if _, err := Parse(strings.NewReader(data)); err == nil {
+		t.Error("Expected error for the unclosed quote.")
+	}
+}
+
+// TestParseReaderInclude tests that the include directives of the
+// io.Reader data are disabled without Includes option.
+func TestParseReaderInclude(t *testing.T) {
+	var pe *ParseError
+
+	// The `#include` line is a comment.
+	data := "#include /etc/hostname\n#include? base.env\nKEY=value\n"
+	entries, err := Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(entries) != 1 || entries[0].Key != "KEY" {
+		t.Errorf("Expected KEY entry only but returns %v.", entries)
+	}
+
+	// Other directives are incorrect expressions.
+	data = "KEY=value\nsource ./fixtures/include/base.env\n"
+	_, err = Parse(strings.NewReader(data))
+	if !errors.As(err, &pe) || pe.Reason != IncludeFailed || pe.Line != 2 {
+		t.Errorf("Expected disabled include at line 2 but returns %v.", err)
+	}
+
+	// The includes are enabled by the option.
+	data = "#include base.env\nsource db/db.env\n"
+	entries, err = Parse(strings.NewReader(data),
+		Includes("./fixtures/include"))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(entries) != 6 || entries[0].Key != "HOST" ||
+		entries[0].Filename != "fixtures/include/base.env" {
+		t.Errorf("Expected entries of the included files but returns %v.",
+			entries)
+	}
+}
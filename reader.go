@@ -0,0 +1,197 @@
+package env
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// exprReader reads an env-file expression by expression. As a rule
+// the expression takes one line, but the value enclosed in quotes can
+// continue on the following lines up to the closing quote (for example:
+// PEM-keys, JSON, etc.). The rules are defined by the dialect syntax.
+//
+// The length of the line isn't limited, but the size of the expression
+// can be limited by the max (the too long expression is reported as
+// ParseError).
+//
+// If the resync is true, the value that is unclosed at the end of the
+// data is reported as ParseError for its first line and the reading
+// continues from the next line (for the lenient and forced modes).
+type exprReader struct {
+	reader  *bufio.Reader
+	syntax  syntax
+	max     int      // maximum size of the expression, 0 is unlimited
+	pending []string // lines that were read but not parsed
+	line    int      // number of the last read line
+	long    bool     // the last read line is longer than max
+	resync  bool     // read the lines after the unclosed value again
+	err     error    // the first reading error
+}
+
+// newExprReader returns a new exprReader that reads from r by the rules
+// of the s syntax, the max is the maximum size of the expression.
+func newExprReader(r io.Reader, s syntax, max int) *exprReader {
+	return &exprReader{reader: bufio.NewReader(r), syntax: s, max: max}
+}
+
+// scan reads the next line, returns false at the end of the data.
+// The line that is longer than max is truncated.
+func (r *exprReader) scan() (string, bool) {
+	if len(r.pending) != 0 {
+		text := r.pending[0]
+		r.pending = r.pending[1:]
+		r.line++
+		return text, true
+	}
+
+	var buf []byte
+	r.long = false
+	for r.err == nil {
+		chunk, err := r.reader.ReadSlice('\n')
+		switch {
+		case r.long:
+			// Keep reading up to the end of the line, but ignore the data.
+		case buf == nil && err != bufio.ErrBufferFull:
+			buf = chunk // the line fits in the buffer
+		default:
+			buf = append(buf, chunk...)
+		}
+
+		if r.max > 0 && len(bytes.TrimRight(buf, "\r\n")) > r.max {
+			buf, r.long = buf[:r.max], true
+		}
+
+		if err == bufio.ErrBufferFull {
+			continue // the line is longer than the buffer
+		} else if err == io.EOF && len(buf) == 0 {
+			return "", false
+		} else if err != nil && err != io.EOF {
+			r.err = err
+			return "", false
+		}
+
+		r.line++
+		text := trimEOL(string(buf))
+		if r.line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff") // remove BOM
+		}
+
+		return text, true
+	}
+
+	return "", false
+}
+
+// tooLong returns ParseError for the expression that is longer than max,
+// the text is the beginning of the expression.
+func (r *exprReader) tooLong(text string, line int) error {
+	if len(text) > 32 {
+		text = text[:32] + "..."
+	}
+
+	return &ParseError{
+		Line:   line,
+		Column: 1,
+		Text:   text,
+		Reason: TooLong,
+		Err:    fmt.Errorf("the size exceeds %d bytes", r.max),
+	}
+}
+
+// Next returns the next non-empty expression and the number of the line
+// where this expression begins. Returns io.EOF if there are no more
+// expressions.
+func (r *exprReader) Next() (exp string, line int, err error) {
+	for {
+		text, ok := r.scan()
+		if !ok {
+			break
+		}
+
+		exp, line = text, r.line
+		if r.long {
+			return "", 0, r.tooLong(exp, line)
+		} else if _, _, ok := r.syntax.include(exp); ok {
+			return exp, line, nil // include directive
+		}
+
+		// Multiline value: join the lines up to the closing quote,
+		// the joiner checks the new line only.
+		if j := r.syntax.open(exp); j != nil {
+			if exp, err = r.join(j, exp, line); err != nil {
+				return "", 0, err
+			}
+		}
+
+		if r.syntax.empty(exp) {
+			continue // ignore empty string or comments
+		}
+
+		return exp, line, nil
+	}
+
+	if r.err != nil {
+		return "", 0, r.err
+	}
+
+	return "", 0, io.EOF
+}
+
+// join reads the lines of the expression that begins with the first
+// line up to the end of the expression. Returns the first line only if
+// the expression is open at the end of the data and the following lines
+// must be parsed separately (see eofSplit).
+func (r *exprReader) join(j joiner, first string, line int) (string, error) {
+	lines, size := []string{first}, len(first)
+	for {
+		text, ok := r.scan()
+		if ok && (r.long || r.max > 0 && size+len(text) >= r.max) {
+			return "", r.tooLong(first, line)
+		} else if ok {
+			lines, size = append(lines, text), size+len(text)+1
+			if !j.add(text) {
+				return strings.Join(lines, "\n"), nil
+			}
+			continue
+		} else if r.err != nil {
+			return "", r.err
+		}
+		break
+	}
+
+	exp := strings.Join(lines, "\n")
+	switch r.syntax.eof() {
+	case eofJoin:
+		return exp, nil
+	case eofSplit:
+		// Parse the following lines separately.
+		r.pending = append(lines[1:], r.pending...)
+		r.line = line
+		return first, nil
+	}
+
+	// The syntax reports the problem (the line continuation
+	// at the end of the data isn't a problem).
+	_, err := r.syntax.parse(exp)
+	if err == nil {
+		return exp, nil
+	} else if pe, ok := err.(*ParseError); ok {
+		pe.Line += line - 1
+	}
+
+	if r.resync {
+		r.pending = append(lines[1:], r.pending...)
+		r.line = line
+	}
+
+	return "", err
+}
+
+// Err returns the first non-EOF error that was encountered
+// while reading the data.
+func (r *exprReader) Err() error {
+	return r.err
+}
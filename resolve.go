@@ -0,0 +1,149 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// CycleError is returned if the values of the env-file refer
+// to each other in a cycle, like: A=${B} and B=${A}.
+type CycleError struct {
+	Filename string   // name of the env-file with the first variable
+	Path     []string // variables of the cycle, like: A, B, A
+}
+
+// Error returns the description of the error as:
+// filename: reference cycle: A -> B -> A.
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("%s: reference cycle: %s",
+		e.Filename, strings.Join(e.Path, " -> "))
+}
+
+// resolver resolves the values of the env-file entries. The references
+// to other entries are resolved recursively regardless of the order
+// of the entries in the env-file, i.e. the file is resolved as
+// a dependency graph.
+type resolver struct {
+	defs      map[string]*Entry // entries of the env-file by key
+	fileFirst bool              // prefer file values to environment
+	strict    bool              // collect undefined variables
+
+	unset     map[string]bool   // variables removed by the directives
+	values    map[string]string // resolved values
+	assigned  map[string]string // values set by `:=` and `=` operators
+	order     []string          // assigned keys in order of resolution
+	stack     []string          // keys that are being resolved
+	undefined []UndefinedVar    // references to the undefined variables
+	err       error             // the first error of the resolving
+}
+
+// newResolver returns resolver for the entries of the env-file. If some
+// key is defined several times the last definition is used if update
+// is true, otherwise the first one.
+func newResolver(entries []Entry, update bool) *resolver {
+	r := &resolver{
+		defs:      make(map[string]*Entry, len(entries)),
+		fileFirst: update,
+		values:    make(map[string]string, len(entries)),
+		assigned:  make(map[string]string),
+	}
+
+	for i := range entries {
+		if _, ok := r.defs[entries[i].Key]; !ok || update {
+			r.defs[entries[i].Key] = &entries[i]
+		}
+	}
+
+	return r
+}
+
+// lookup returns value of the variable for the expander. The variable
+// defined in the env-file is resolved recursively if file values are
+// preferred or the variable isn't set in the environment. The reference
+// of the variable to itself (like PATH=$PATH:/bin) is resolved by
+// the environment.
+func (r *resolver) lookup(key string) (string, bool) {
+	if r.err != nil {
+		return "", false
+	}
+
+	_, inEnv := r.getenv(key)
+	self := len(r.stack) != 0 && r.stack[len(r.stack)-1] == key
+	if _, ok := r.defs[key]; ok && !self && (r.fileFirst || !inEnv) {
+		value, err := r.resolve(key)
+		if err != nil {
+			r.err = err
+			return "", false
+		}
+		return value, true
+	}
+
+	if value, ok := r.assigned[key]; ok {
+		return value, true
+	}
+
+	return r.getenv(key)
+}
+
+// getenv returns value of the environment variable, the variables
+// removed by the `unset` directive aren't set.
+func (r *resolver) getenv(key string) (string, bool) {
+	if r.unset[key] {
+		return "", false
+	}
+
+	return os.LookupEnv(key)
+}
+
+// assign sets the variable by the `:=` and `=` operators.
+func (r *resolver) assign(key, value string) error {
+	if _, ok := r.assigned[key]; !ok {
+		r.order = append(r.order, key)
+	}
+
+	r.assigned[key] = value
+	return nil
+}
+
+// resolve returns resolved value of the env-file entry by key.
+func (r *resolver) resolve(key string) (string, error) {
+	if value, ok := r.values[key]; ok {
+		return value, nil
+	}
+
+	// Cycle detection.
+	for i, item := range r.stack {
+		if item == key {
+			path := append(append([]string{}, r.stack[i:]...), key)
+			return "", &CycleError{
+				Filename: r.defs[path[0]].Filename,
+				Path:     path,
+			}
+		}
+	}
+
+	// Resolve the value, the references are resolved by lookup method.
+	e := r.defs[key]
+	x := &expander{lookup: r.lookup, set: r.assign, strict: r.strict}
+
+	r.stack = append(r.stack, key)
+	value, err := unquote(e.raw, e.Quote, x)
+	r.stack = r.stack[:len(r.stack)-1]
+
+	switch {
+	case r.err != nil:
+		return "", r.err // error in the referenced entry
+	case err != nil:
+		return "", fmt.Errorf("%s:%d: %w", e.Filename, e.Line, err)
+	}
+
+	for _, v := range x.undefined {
+		v.Filename = e.Filename
+		v.Line += e.Line - 1
+		r.undefined = append(r.undefined, v)
+	}
+
+	r.values[key] = value
+	return value, nil
+}
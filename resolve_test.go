@@ -0,0 +1,118 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestReadParseStoreGraph tests resolving of the forward
+// and chained references.
+func TestReadParseStoreGraph(t *testing.T) {
+	var tests = map[string]string{
+		"URL":    "http://0.0.0.0:8080/",
+		"ADDR":   "0.0.0.0:8080",
+		"SCHEME": "http",
+		"PATH":   "/opt/bin:/bin",
+	}
+
+	Clear()
+	Set("PATH", "/bin")
+	err := ReadParseStore("./fixtures/graph.env", true, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, value := range tests {
+		if v := Get(key); value != v {
+			t.Errorf("Incorrect value for `%s` key: `%s`!=`%s`", key, value, v)
+		}
+	}
+}
+
+// TestReadParseStoreCycle tests detection of the reference cycle.
+func TestReadParseStoreCycle(t *testing.T) {
+	var ce *CycleError
+
+	Clear()
+	err := ReadParseStore("./fixtures/cycle.env", true, false, false)
+	if !errors.As(err, &ce) {
+		t.Fatalf("Must be a CycleError: %v", err)
+	}
+
+	path := []string{"KEY_1", "KEY_3", "KEY_2", "KEY_1"}
+	if !reflect.DeepEqual(ce.Path, path) {
+		t.Errorf("Expected %v path but returns %v.", path, ce.Path)
+	}
+
+	msg := "./fixtures/cycle.env: reference cycle: " +
+		"KEY_1 -> KEY_3 -> KEY_2 -> KEY_1"
+	if err.Error() != msg {
+		t.Errorf("Expected `%s` but returns `%s`.", msg, err)
+	}
+
+	if Exists("KEY_0") {
+		t.Error("The values were stored.")
+	}
+}
+
+// TestReadParseStorePrecedence tests FileFirst and EnvFirst options.
+func TestReadParseStorePrecedence(t *testing.T) {
+	type sample struct {
+		load func(string, ...Option) error
+		opts []Option
+		host string
+		addr string
+	}
+
+	var tests = []sample{
+		{Load, nil, "localhost", "localhost:8080"},
+		{Load, []Option{FileFirst()}, "localhost", "0.0.0.0:8080"},
+		{Update, nil, "0.0.0.0", "0.0.0.0:8080"},
+		{Update, []Option{EnvFirst()}, "0.0.0.0", "localhost:8080"},
+	}
+
+	for i, test := range tests {
+		Clear()
+		Set("HOST", "localhost")
+		if err := test.load("./fixtures/graph.env", test.opts...); err != nil {
+			t.Fatal(err)
+		}
+
+		if v := Get("HOST"); v != test.host {
+			t.Errorf("%d: expected `%s` host but returns `%s`.",
+				i, test.host, v)
+		}
+
+		if v := Get("ADDR"); v != test.addr {
+			t.Errorf("%d: expected `%s` addr but returns `%s`.",
+				i, test.addr, v)
+		}
+	}
+}
+
+// TestStoreAssignedOrder tests that the variables set by the `:=`
+// and `=` operators are stored in order of resolution.
+func TestStoreAssignedOrder(t *testing.T) {
+	var (
+		expected = []string{"URL", "SCHEME", "HOST", "PORT", "PATH_"}
+		data     = "URL=${SCHEME:=http}://${HOST:=localhost}:${PORT=80}" +
+			"/${PATH_:=api}\n"
+	)
+
+	for i := 0; i < 20; i++ {
+		Clear()
+		entries, err := Parse(strings.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		keys, err := store(entries, &options{expand: true})
+		if err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(keys, expected) {
+			t.Fatalf("Expected %v but returns %v.", expected, keys)
+		}
+	}
+}
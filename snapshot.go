@@ -0,0 +1,141 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Snapshot is the saved state of the environment.
+type Snapshot struct {
+	keys []string          // keys in order of the environment
+	vars map[string]string // values by key
+}
+
+// Changes is the difference between two snapshots of the environment,
+// the keys are sorted.
+type Changes struct {
+	Added   []string // keys that are set only in the other snapshot
+	Removed []string // keys that are set only in the snapshot
+	Changed []string // keys with different values
+}
+
+// Empty returns true if there are no changes.
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+// NewSnapshot saves the current state of the environment.
+//
+// Example:
+//
+//    snapshot := env.NewSnapshot()
+//    if err := env.Update("plugin.env"); err != nil {
+//        // something went wrong
+//    }
+//
+//    // Log the changes and roll them back.
+//    changes := snapshot.Diff(env.NewSnapshot())
+//    log.Println(changes.Added, changes.Removed, changes.Changed)
+//    if err := snapshot.Restore(); err != nil {
+//        // something went wrong
+//    }
+func NewSnapshot() *Snapshot {
+	environ := os.Environ()
+	s := &Snapshot{
+		keys: make([]string, 0, len(environ)),
+		vars: make(map[string]string, len(environ)),
+	}
+
+	for _, item := range environ {
+		if len(item) == 0 {
+			continue
+		}
+
+		// On Windows the key can begin with the `=` sign, like: =C:=C:\.
+		// The first value of the repeated key is used as by os.Getenv.
+		i := strings.IndexByte(item[1:], '=') + 1
+		if key := item[:i]; i > 0 {
+			if _, ok := s.vars[key]; !ok {
+				s.keys = append(s.keys, key)
+				s.vars[key] = item[i+1:]
+			}
+		}
+	}
+
+	return s
+}
+
+// Keys returns the keys of the variables in order of the environment.
+func (s *Snapshot) Keys() []string {
+	return append([]string(nil), s.keys...)
+}
+
+// LookupEnv returns the saved value of the variable by key, the ok
+// is false if the variable wasn't set. So the snapshot can be used
+// as a source of the UnmarshalFrom function.
+func (s *Snapshot) LookupEnv(key string) (value string, ok bool) {
+	value, ok = s.vars[key]
+	return
+}
+
+// Restore replaces the environment with the saved variables: the new
+// variables are deleted and the changed and deleted variables are
+// restored in order of the snapshot, the unchanged variables aren't
+// touched. The Windows variables that begin with the `=` sign (like
+// =C:) are skipped. Restore continues after an error and returns all
+// errors together.
+func (s *Snapshot) Restore() error {
+	var (
+		errs    []error
+		current = NewSnapshot()
+	)
+
+	for _, key := range current.keys {
+		if _, ok := s.vars[key]; !ok && !strings.HasPrefix(key, "=") {
+			if err := os.Unsetenv(key); err != nil {
+				errs = append(errs, fmt.Errorf("unset %s: %w", key, err))
+			}
+		}
+	}
+
+	for _, key := range s.keys {
+		value, ok := current.vars[key]
+		if ok && value == s.vars[key] || strings.HasPrefix(key, "=") {
+			continue
+		}
+
+		if err := os.Setenv(key, s.vars[key]); err != nil {
+			errs = append(errs, fmt.Errorf("set %s: %w", key, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Diff returns the changes of the other snapshot relative to the
+// snapshot: the added, removed and changed keys.
+func (s *Snapshot) Diff(other *Snapshot) Changes {
+	var c Changes
+	for _, key := range s.keys {
+		if value, ok := other.vars[key]; !ok {
+			c.Removed = append(c.Removed, key)
+		} else if value != s.vars[key] {
+			c.Changed = append(c.Changed, key)
+		}
+	}
+
+	for _, key := range other.keys {
+		if _, ok := s.vars[key]; !ok {
+			c.Added = append(c.Added, key)
+		}
+	}
+
+	sort.Strings(c.Added)
+	sort.Strings(c.Removed)
+	sort.Strings(c.Changed)
+
+	return c
+}
@@ -0,0 +1,87 @@
+package env
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// TestSnapshot tests NewSnapshot function and Snapshot methods.
+func TestSnapshot(t *testing.T) {
+	Clear()
+	Set("HOST", "localhost")
+	Set("PORT", "8080")
+	Set("USER", "goloop")
+
+	snapshot := NewSnapshot()
+	if keys := snapshot.Keys(); len(keys) != 3 {
+		t.Errorf("Expected 3 keys but returns %v.", keys)
+	}
+
+	if v, ok := snapshot.LookupEnv("PORT"); !ok || v != "8080" {
+		t.Errorf("Expected `8080` but returns `%s`.", v)
+	}
+
+	// Changes.
+	Set("PORT", "80")
+	Set("DEBUG", "true")
+	Set("CACHE", "")
+	Unset("USER")
+
+	changes := snapshot.Diff(NewSnapshot())
+	expected := Changes{
+		Added:   []string{"CACHE", "DEBUG"},
+		Removed: []string{"USER"},
+		Changed: []string{"PORT"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %v but returns %v.", expected, changes)
+	}
+
+	// Restore.
+	if err := snapshot.Restore(); err != nil {
+		t.Fatal(err)
+	}
+
+	if changes := snapshot.Diff(NewSnapshot()); !changes.Empty() {
+		t.Errorf("The environment isn't restored: %v.", changes)
+	}
+
+	if v := os.Getenv("PORT"); v != "8080" {
+		t.Errorf("Expected `8080` but returns `%s`.", v)
+	}
+
+	// Keys returns a copy.
+	snapshot.Keys()[0] = "KEY"
+	if keys := snapshot.Keys(); keys[0] == "KEY" {
+		t.Error("Keys returns the internal slice.")
+	}
+}
+
+// TestSnapshotRestore tests that Restore continues after an error
+// and skips the Windows variables.
+func TestSnapshotRestore(t *testing.T) {
+	Clear()
+	Set("DEBUG", "true")
+
+	snapshot := &Snapshot{
+		keys: []string{"BAD=KEY", "=C:", "HOST"},
+		vars: map[string]string{
+			"BAD=KEY": "value",
+			"=C:":     "C:\\",
+			"HOST":    "localhost",
+		},
+	}
+
+	if err := snapshot.Restore(); err == nil {
+		t.Error("Expected an error for the incorrect key.")
+	}
+
+	if v := os.Getenv("HOST"); v != "localhost" {
+		t.Errorf("Expected `localhost` but returns `%s`.", v)
+	}
+
+	if _, ok := os.LookupEnv("DEBUG"); ok {
+		t.Error("The new variable isn't deleted.")
+	}
+}
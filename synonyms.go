@@ -44,7 +44,15 @@ func Environ() []string {
 // of the current environment variables. References to undefined
 // variables are replaced by the empty string.
 //
-// P.s. It is synonym for the os.ExpandEnv.
+// The POSIX parameter expansion operators like ${var:-default} are
+// supported. The reference that cannot be expanded is replaced by the
+// empty string, for example ${var:?message} with undefined variable
+// (the reference with incorrect syntax is kept as is), use the
+// Interpolate function to get an error.
 func Expand(value string) string {
-	return os.Expand(value, os.Getenv)
+	x := newExpander()
+	x.lenient = true
+
+	value, _ = x.expand(value, false)
+	return value
 }
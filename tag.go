@@ -18,13 +18,17 @@ var correctKeyRgx = regexp.MustCompile(`^[A-Za-z_]{1}\w*$`)
 //
 // Input:
 //   - ft is field's tag as string;
+//   - valid is validator of the key (see KeyPolicy);
 //
 // Output:
 //   - key is environment variable name;
 //   - value is default value (if key not exists in environment);
 //   - sep is item separator (for lists only);
 //   - err is error id.
-func splitFieldTag(ft string) (key, value, sep string, err error) {
+func splitFieldTag(
+	ft string,
+	valid func(string) bool,
+) (key, value, sep string, err error) {
 	var (
 		scope  = []*string{&key, &value, &sep}
 		covers = map[string]string{"'": "'", "\"": "\"", "{": "}"}
@@ -39,7 +43,7 @@ func splitFieldTag(ft string) (key, value, sep string, err error) {
 	}
 
 	// Checking key for correctness.
-	if len(key) != 0 && !correctKeyRgx.Match([]byte(key)) {
+	if len(key) != 0 && !valid(key) {
 		err = fmt.Errorf("incorrect key %s", key)
 	}
 
@@ -92,6 +96,127 @@ func splitFieldTag(ft string) (key, value, sep string, err error) {
 	return
 }
 
+// fieldTag is the parsed `env` tag of the structure field.
+type fieldTag struct {
+	key      string // environment variable name
+	value    string // default value
+	sep      string // item separator (for lists only)
+	required bool   // the variable must be set or have a default value
+	rules    []rule // constraints of the value, like min=1
+}
+
+// parseFieldTag parses the field's tag as `key[,value[,sep[,options]]]`,
+// where the options are separated by commas, like:
+// `env:"PORT,8080,,required,min=1,max=65535"`.
+func parseFieldTag(ft string, valid func(string) bool) (fieldTag, error) {
+	var tag fieldTag
+
+	key, value, tail, err := splitFieldTag(ft, valid)
+	if err != nil {
+		return tag, err
+	}
+
+	sep, opts, err := splitTagOptions(tail)
+	if err != nil {
+		return tag, err
+	}
+	tag.key, tag.value, tag.sep = key, value, sep
+
+	for _, opt := range opts {
+		switch opt {
+		case "required":
+			tag.required = true
+		default:
+			r, err := parseRule(opt)
+			if err != nil {
+				return tag, err
+			}
+			tag.rules = append(tag.rules, r)
+		}
+	}
+
+	return tag, nil
+}
+
+// splitTagOptions separates the item separator and the options of the
+// tail of the field's tag, like: `:,required`. The separator is the text
+// up to the comma, except that the comma followed by the comma (or at
+// the end) is the separator itself, and the empty separator before the
+// options is the default one. The option can contain the comma inside
+// single quotes, like: `regex='^[a-z]{1,8}$'`.
+//
+// The text after the comma is taken as options only if all its items
+// are known options, otherwise the whole tail is the separator (like
+// `a,b` for the `KEY,,a,b` tag). Returns an error if the option is
+// written in place of the separator, like: `PORT,,min=1` instead of
+// `PORT,,,min=1`.
+func splitTagOptions(tail string) (sep string, opts []string, err error) {
+	i, rest := strings.IndexByte(tail, ','), ""
+	switch {
+	case i < 0:
+		sep = tail
+	case i == 0 && (len(tail) == 1 || tail[1] == ','):
+		sep, rest = ",", tail[1:]
+	case i == 0:
+		sep, rest = ":", tail
+	default:
+		sep, rest = strings.Trim(tail[:i], " _"), tail[i:]
+	}
+
+	if isTagOption(sep) {
+		return "", nil, fmt.Errorf("option %s is in place of the "+
+			"separator", sep)
+	}
+
+	// The rest is empty or begins with the comma.
+	for len(rest) != 0 {
+		var quoted bool
+
+		rest = rest[1:]
+		end := 0
+		for ; end < len(rest) && (quoted || rest[end] != ','); end++ {
+			if rest[end] == '\'' {
+				quoted = !quoted
+			}
+		}
+
+		opt := strings.TrimSpace(rest[:end])
+		switch {
+		case !isTagOption(opt):
+			// Not an option: the comma is a part of the separator.
+			return strings.Trim(tail, " _"), nil, nil
+		case quoted:
+			err = fmt.Errorf("missing `'` - closing character "+
+				"to option: %s", rest)
+		}
+
+		opts, rest = append(opts, opt), rest[end:]
+	}
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return sep, opts, nil
+}
+
+// isTagOption returns true if the text is the option of the field's
+// tag, like: required or min=1.
+func isTagOption(text string) bool {
+	name := strings.TrimSpace(text)
+	if i := strings.IndexByte(name, '='); i >= 0 {
+		name = strings.TrimSpace(name[:i])
+	}
+
+	switch name {
+	case "required", "min", "max", "len", "oneof", "regex",
+		"nonempty", "url", "hostport", "email":
+		return true
+	}
+
+	return false
+}
+
 /*
 // Rules for handling tags.
 var (
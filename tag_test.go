@@ -34,7 +34,7 @@ func TestSplitFieldTag(t *testing.T) {
 
 	// Tests.
 	for _, check := range correct {
-		key, value, sep, err := splitFieldTag(check.tag)
+		key, value, sep, err := splitFieldTag(check.tag, POSIXKeys)
 		if err != nil {
 			t.Error(err)
 		}
@@ -54,9 +54,54 @@ func TestSplitFieldTag(t *testing.T) {
 
 	// Tests.
 	for _, sample := range incorrect {
-		_, _, _, err := splitFieldTag(sample)
+		_, _, _, err := splitFieldTag(sample, POSIXKeys)
 		if err == nil {
 			t.Error("there must be a error for expression:", sample)
 		}
 	}
 }
+
+// TestParseFieldTag tests parseFieldTag function.
+func TestParseFieldTag(t *testing.T) {
+	tests := []struct {
+		tag      string
+		sep      string
+		required bool
+	}{
+		{"KEY", ":", false},
+		{"KEY,,!", "!", false},
+		{"KEY,,,", ",", false},
+		{"KEY,,,required", ":", true},
+		{"KEY,,!,required", "!", true},
+		{"KEY,,,,required", ",", true},
+		{"KEY,'a,b',;, required", ";", true},
+		{"KEY,,a,b", "a,b", false},
+		{"KEY,,,unknown", ",unknown", false},
+		{"KEY,,:,'required", ":,'required", false},
+		{"KEY,,:,required,", ":,required,", false},
+	}
+
+	for _, test := range tests {
+		tag, err := parseFieldTag(test.tag, POSIXKeys)
+		if err != nil {
+			t.Errorf("%s: %v", test.tag, err)
+		} else if tag.key != "KEY" || tag.sep != test.sep ||
+			tag.required != test.required {
+			t.Errorf("%s: incorrect result %+v", test.tag, tag)
+		}
+	}
+
+	for _, sample := range []string{
+		"KEY,,:,regex='[a-z]",
+		"KEY,,:,required,min=a",
+		// The options are in place of the separator.
+		"PORT,,min=1",
+		"HOST,,required",
+		"PORT,8080,required",
+		"PORT,,max = 9,required",
+	} {
+		if _, err := parseFieldTag(sample, POSIXKeys); err == nil {
+			t.Error("there must be a error for expression:", sample)
+		}
+	}
+}
@@ -4,98 +4,240 @@ import (
 	"fmt"
 	"math"
 	"reflect"
-	"regexp"
 	"strconv"
 	"strings"
-	"time"
 )
 
-var (
-	emptyRegex = regexp.MustCompile(`^(\s*)$|^(\s*[#].*)$`)
-	valueRegex = regexp.MustCompile(`^=[^\s].*`)
-	keyRegex   = regexp.MustCompile(
-		`^(?:\s*)?(?:export\s+)?(?P<key>[a-zA-Z_][a-zA-Z_0-9]*)=`,
-	)
-)
+// isOpenQuote returns true if the value of the expression begins with
+// a quote, but the closing quote is missing, or the heredoc block isn't
+// closed by the delimiter, i.e. the value continues on the next line.
+func isOpenQuote(exp string) bool {
+	if _, delimiter, ok := parseHeredoc(exp); ok {
+		i := strings.LastIndexByte(exp, '\n')
+		return i < 0 || exp[i+1:] != delimiter
+	}
+
+	_, pos := lexAssign(exp)
+	if pos == 0 || pos >= len(exp) {
+		return false
+	}
 
-// isEmpty returns true if string contains separators or comment only.
-func isEmpty(str string) bool {
-	return emptyRegex.Match([]byte(str))
+	value := exp[pos:]
+	if value[0] != '"' && value[0] != '\'' {
+		return false
+	}
+
+	return closingQuote(value) < 0
 }
 
-// removeInlineComment removes the comment in the string.
-// Only in strings where the value is enclosed in quotes.
-func removeInlineComment(str, quote string) string {
-	// If the comment is in the string.
-	if strings.Contains(str, "#") {
-		chunks := strings.Split(str, "#")
-		for i := range chunks {
-			str := strings.Join(chunks[:i], "#")
-			if len(str) > 0 && strings.Count(str, quote)%2 == 0 {
-				return strings.TrimSpace(str)
-			}
+// isContinued returns true if the unquoted value of the expression ends
+// with a backslash, i.e. the value continues on the next line, like:
+//
+//    KEY=value\
+//    continuation
+//
+// The escaped backslash at the end of the line (`\\`) and the backslash
+// in the comment don't join the lines.
+func isContinued(exp string) bool {
+	_, pos := lexAssign(exp)
+	if pos == 0 || !isUnquoted(exp) {
+		return false
+	}
+
+	n := len(exp) - len(strings.TrimRight(exp, "\\"))
+	return n%2 == 1 && strings.IndexByte(exp[pos:], '#') < 0
+}
+
+// isUnquoted returns true if the expression is the assignment
+// of the unquoted value (but not the heredoc block).
+func isUnquoted(exp string) bool {
+	if _, _, ok := parseHeredoc(exp); ok {
+		return false
+	}
+
+	_, pos := lexAssign(exp)
+	if pos == 0 || pos >= len(exp) {
+		return false
+	}
+
+	return exp[pos] != '"' && exp[pos] != '\''
+}
+
+// closingQuote returns the index of the quote that closes the value
+// or -1 if the closing quote is missing. The value must begin with
+// a quote. The escaped quotes are skipped in the double-quoted value,
+// the single-quoted value cannot contain a single quote at all.
+func closingQuote(value string) int {
+	quote := value[0]
+	for i := 1; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && quote == '"':
+			i++ // skip escaped character
+		case value[i] == quote:
+			return i
 		}
 	}
-	return str
+
+	return -1
 }
 
 // parseExpression breaks expression into key and value, ignore
-// comments and any spaces.
+// comments and any spaces. The quote is the quoting style of the value.
+// The valid validates the key, POSIXKeys is used if it's nil.
+// Returns *ParseError with position relative to the exp in case of
+// failure.
 //
-// Note: value must be an expression.
-func parseExpression(exp string) (key, value string, err error) {
-	var (
-		quote  string = "\""
-		marker string = fmt.Sprintf("<::%d::>", time.Now().Unix())
-	)
-
-	// Get key.
-	// Remove `export` prefix, `=` suffix and trim spaces.
-	tmp := keyRegex.FindStringSubmatch(exp)
-	if len(tmp) < 2 {
-		err = fmt.Errorf("missing variable name")
+// Note: value must be an expression. The escape sequences in the
+// value aren't interpreted, use unquote function for it.
+func parseExpression(
+	exp string,
+	valid func(string) bool,
+) (key, value string, quote Quoting, err error) {
+	if valid == nil {
+		valid = POSIXKeys
+	}
+
+	// Heredoc block: the lines between KEY<<EOF and EOF lines.
+	if key, delimiter, ok := parseHeredoc(exp); ok {
+		if !valid(key) {
+			offset := len(exp) - len(strings.TrimLeft(exp, " \t"))
+			return "", "", 0, newParseError(IncorrectKey, exp, offset)
+		}
+
+		lines := strings.Split(exp, "\n")
+		if n := len(lines); n < 2 || lines[n-1] != delimiter {
+			offset := strings.Index(exp, "<<")
+			return "", "", 0, newParseError(UnclosedHeredoc, exp, offset)
+		}
+
+		value = strings.Join(lines[1:len(lines)-1], "\n")
+		return key, value, HeredocQuoted, nil
+	}
+
+	// Get key, the `export` prefix and spaces are skipped.
+	key, pos := lexAssign(exp)
+	if offset := len(exp) - len(strings.TrimLeft(exp, " \t")); key == "" {
+		err = newParseError(MissingKey, exp, offset)
+		return
+	} else if !valid(key) {
+		err = newParseError(IncorrectKey, exp, offset)
 		return
 	}
-	key = tmp[1]
 
-	// Get value.
-	// ... the `=` sign in the string.
-	value = exp[strings.Index(exp, "="):]
-	if !valueRegex.Match([]byte(value)) {
-		err = fmt.Errorf("incorrect value: %s", value)
+	// Get value, it cannot begin with a space.
+	if pos == len(exp) || isSpace(exp[pos]) {
+		err = newParseError(IncorrectValue, exp, pos)
 		return
 	}
-	value = strings.TrimSpace(value[1:])
+	value = strings.TrimSpace(exp[pos:])
 
 	switch {
-	case strings.HasPrefix(value, "'"):
-		quote = "'"
-		fallthrough
-	case strings.HasPrefix(value, "\""):
-		// Replace escaped quotes, remove comment in the string,
-		// check begin- and end- quotes and back escaped quotes.
-		value = strings.Replace(value, fmt.Sprintf("\\%s", quote), marker, -1)
-		value = removeInlineComment(value, quote)
-		if strings.Count(value, quote)%2 != 0 { // begin- and end- quotes
-			err = fmt.Errorf("incorrect value: %s", value)
+	case strings.HasPrefix(value, "'"), strings.HasPrefix(value, "\""):
+		// Find the closing quote, only the comment
+		// can be after it.
+		end := closingQuote(value)
+		if end < 0 {
+			err = newParseError(UnclosedQuote, exp, pos)
+			return
+		}
+
+		tail := strings.TrimSpace(value[end+1:])
+		if len(tail) != 0 && tail[0] != '#' {
+			offset := pos + len(value) - len(tail)
+			err = newParseError(IncorrectValue, exp, offset)
 			return
 		}
-		value = value[1 : len(value)-1] // remove begin- and end- quotes
-		// ... change `\"` and `\'` to `"` and `'`.
-		value = strings.Replace(value, marker, quote, -1)
+
+		quote = SingleQuoted
+		if value[0] == '"' {
+			quote = DoubleQuoted
+		}
+		value = value[1:end] // remove begin- and end- quotes
+
+		// Check escape sequences.
+		for i := 0; quote == DoubleQuoted && i < len(value); i++ {
+			if value[i] == '\\' {
+				_, n, ok := decodeEscape(value[i:])
+				if !ok {
+					err = newParseError(IncorrectEscape, exp, pos+1+i)
+					return
+				}
+				i += n - 1
+			}
+		}
 	default:
-		if strings.Contains(value, "#") {
-			// Split by sharp sign and for string without quotes -
-			// the first element has the meaning only.
-			chunks := strings.Split(value, "#")
-			chunks = strings.Split(chunks[0], " ")
-			value = strings.TrimSpace(chunks[0])
+		if i := strings.IndexByte(value, '#'); i >= 0 {
+			// Cut the comment and for string without quotes -
+			// the first word has the meaning only.
+			value = value[:i]
+			if i = strings.IndexByte(value, ' '); i >= 0 {
+				value = value[:i]
+			}
+			value = strings.TrimSpace(value)
 		}
 	}
 
 	return
 }
 
+// decodeEscape interprets the escape sequence at the beginning of the
+// str and returns the result and length of the sequence. Returns false
+// if the escape sequence is incorrect.
+//
+// Unknown escape sequence is taken as is (i.e. "\a" is "\a").
+func decodeEscape(str string) (result string, n int, ok bool) {
+	if len(str) < 2 {
+		return str, len(str), true
+	}
+
+	switch str[1] {
+	case 'n':
+		return "\n", 2, true
+	case 't':
+		return "\t", 2, true
+	case 'r':
+		return "\r", 2, true
+	case '\\', '"', '$':
+		return str[1:2], 2, true
+	case 'u':
+		if len(str) < 6 {
+			return "", 0, false
+		}
+
+		r, err := strconv.ParseUint(str[2:6], 16, 32)
+		if err != nil {
+			return "", 0, false
+		}
+
+		return string(rune(r)), 6, true
+	}
+
+	return str[:2], 2, true
+}
+
+// unquote interprets the value according to its quoting and replaces
+// ${var} or $var in the string using x expander. If x is nil the
+// variables are not replaced.
+//
+// The rules are:
+//    - single-quoted value and heredoc block are fully literal;
+//    - double-quoted value interprets escape sequences \n, \t, \r, \\,
+//      \", \$ and \uXXXX and replaces variables, but the escaped
+//      characters are never replaced (i.e. "\$HOME" is "$HOME");
+//    - unquoted value is taken as is and replaces variables.
+func unquote(value string, quote Quoting, x *expander) (string, error) {
+	switch {
+	case quote == SingleQuoted || quote == HeredocQuoted:
+		return value, nil
+	case x == nil && quote == Unquoted:
+		return value, nil
+	case x == nil:
+		x = &expander{} // interpret escape sequences only
+	}
+
+	return x.expand(value, quote == DoubleQuoted)
+}
+
 // strToIntKind convert string to int64 type with checking for conversion
 // to intX type. Returns default value for int type if value is empty.
 //
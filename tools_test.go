@@ -65,22 +65,6 @@ func TestIsEmpty(t *testing.T) {
 	}
 }
 
-// TestRemoveInlineComment tests removeCommentInline function.
-func TestRemoveInlineComment(t *testing.T) {
-	var items = [][]string{
-		{`="value" # comment`, `="value"`},
-		{`="value's" # comment`, `="value's"`},
-		{`="value # here" # comment`, `="value # here"`},
-	}
-
-	for _, item := range items {
-		test, result := item[0], item[1]
-		if v := removeInlineComment(test, "\""); v != result {
-			t.Errorf("The `%s` doesn't match  `%s`.", v, result)
-		}
-	}
-}
-
 // TestParseExpressionIncorrectKey tests parseExpression function.
 // The function returns an error on the wrong key.
 func TestParseExpressionIncorrectKey(t *testing.T) {
@@ -94,7 +78,7 @@ func TestParseExpressionIncorrectKey(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		if _, _, err := parseExpression(test); err == nil {
+		if _, _, _, err := parseExpression(test, nil); err == nil {
 			t.Errorf("For `%s` value must be an error.", test)
 		}
 	}
@@ -108,12 +92,12 @@ func TestParseExpressionIncorrectValue(t *testing.T) {
 		`KEY="value`,        // not end-quote
 		`KEY='value"`,       // end-quote does not match
 		`KEY="value\"`,      // end-quote part of the string \"
-		`KEY='value\'`,      // end-quote part of the string \'
 		`KEY= "value"`,      // space after equal sign
+		`KEY="value" tail`,  // not a comment after the end-quote
 	}
 
 	for _, test := range tests {
-		if _, _, err := parseExpression(test); err == nil {
+		if _, _, _, err := parseExpression(test, nil); err == nil {
 			t.Errorf("For `%s` value must be an error.", test)
 		}
 	}
@@ -121,16 +105,63 @@ func TestParseExpressionIncorrectValue(t *testing.T) {
 
 // TestParseExpression tests parseExpression function.
 func TestParseExpression(t *testing.T) {
-	var tests = []string{
-		`export KEY="value"`,
-		`KEY="value"`,
-		`KEY="value" # comment`,
+	var tests = [][]string{
+		{`export KEY="value"`, `value`},
+		{`KEY="value"`, `value`},
+		{`KEY="value" # comment`, `value`},
+		{`KEY="value's" # comment`, `value's`},
+		{`KEY="value # here" # comment`, `value # here`},
+		{`KEY='value\'`, `value\`},
+		{`KEY=value # comment`, `value`},
 	}
 
 	for _, test := range tests {
-		if k, v, _ := parseExpression(test); k != "KEY" || v != "value" {
+		exp, value := test[0], test[1]
+		if k, v, _, _ := parseExpression(exp, nil); k != "KEY" || v != value {
 			t.Errorf("Incorrect parsing for `%s` value, "+
-				"whre KEY=`%s` and VALUE=`%s`", test, k, v)
+				"whre KEY=`%s` and VALUE=`%s`", exp, k, v)
+		}
+	}
+}
+
+// TestUnquote tests unquote function.
+func TestUnquote(t *testing.T) {
+	type sample struct {
+		value  string
+		quote  Quoting
+		result string
+	}
+
+	var (
+		lookup = func(key string) (string, bool) {
+			return "<" + key + ">", true
+		}
+		correct = []sample{
+			{`a\nb\tc`, DoubleQuoted, "a\nb\tc"},
+			{`a\\b \"c\"`, DoubleQuoted, `a\b "c"`},
+			{`\u0041\u00e9`, DoubleQuoted, "A\u00e9"},
+			{`\$HOME is $HOME`, DoubleQuoted, "$HOME is <HOME>"},
+			{`\\$HOME`, DoubleQuoted, `\<HOME>`},
+			{`it\'s \a`, DoubleQuoted, `it\'s \a`},
+			{`a\nb $HOME \$HOME`, SingleQuoted, `a\nb $HOME \$HOME`},
+			{`${HOME}/bin`, Unquoted, "<HOME>/bin"},
+		}
+		incorrect = []string{`\u00`, `\uXYZW`}
+	)
+
+	for _, s := range correct {
+		r, err := unquote(s.value, s.quote, &expander{lookup: lookup})
+		if err != nil {
+			t.Error(err)
+		} else if r != s.result {
+			t.Errorf("For `%s` expected `%s` but returns `%s`.",
+				s.value, s.result, r)
+		}
+	}
+
+	for _, value := range incorrect {
+		if _, err := unquote(value, DoubleQuoted, nil); err == nil {
+			t.Errorf("For `%s` value must be an error.", value)
 		}
 	}
 }
@@ -325,3 +356,74 @@ func TestStrToFloatKind(t *testing.T) {
 		}
 	}
 }
+
+// TestIsOpenQuote tests isOpenQuote function.
+func TestIsOpenQuote(t *testing.T) {
+	var tests = map[string]bool{
+		`KEY="value"`:           false,
+		`KEY=value`:             false,
+		`KEY=`:                  false,
+		`KEY="value`:            true,
+		`export KEY='{`:         true,
+		`KEY="value\"`:          true,
+		`KEY="value\" # "`:      false,
+		`KEY='it''s' # comment`: false,
+	}
+
+	for test, result := range tests {
+		if v := isOpenQuote(test); v != result {
+			t.Errorf("For `%s` expected %t but returns %t.", test, result, v)
+		}
+	}
+}
+
+// TestIsContinued tests isContinued function.
+func TestIsContinued(t *testing.T) {
+	var tests = map[string]bool{
+		`KEY=value`:             false,
+		`KEY=value\`:            true,
+		`KEY=value\\`:           false,
+		`KEY=value\\\`:          true,
+		"KEY=first\\\nsecond\\": true,
+		`KEY="value\`:           false,
+		`KEY=value # comment \`: false,
+		`KEY<<\`:                false,
+		`# comment \`:           false,
+	}
+
+	for test, result := range tests {
+		if v := isContinued(test); v != result {
+			t.Errorf("For `%s` expected %t but returns %t.", test, result, v)
+		}
+	}
+}
+
+// TestParseInclude tests parseInclude function.
+func TestParseInclude(t *testing.T) {
+	type sample struct {
+		path     string
+		optional bool
+		ok       bool
+	}
+
+	var tests = map[string]sample{
+		`source ./db.env`:              {"./db.env", false, true},
+		`  . db.env # comment`:         {"db.env", false, true},
+		`#include db.env`:              {"db.env", false, true},
+		`#include? "my secrets.env"`:   {"my secrets.env", true, true},
+		`source? '/etc/app.env'`:       {"/etc/app.env", true, true},
+		`# include is a comment`:       {"", false, false},
+		`source=value`:                 {"", false, false},
+		`source ./a.env ./b.env`:       {"", false, false},
+		`export KEY="source ./db.env"`: {"", false, false},
+	}
+
+	for test, result := range tests {
+		path, optional, ok := parseInclude(test)
+		if path != result.path || optional != result.optional ||
+			ok != result.ok {
+			t.Errorf("For `%s` expected %v but returns %v.",
+				test, result, sample{path, optional, ok})
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package env
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// rule is the constraint of the field's tag, like: min=1 or url.
+type rule struct {
+	name string         // name of the constraint
+	arg  string         // argument of the constraint
+	num  float64        // numeric argument of the min, max and len
+	set  []string       // allowed values of the oneof
+	re   *regexp.Regexp // expression of the regex
+}
+
+// String returns the constraint as it's written in the tag.
+func (r rule) String() string {
+	if len(r.arg) == 0 {
+		return r.name
+	}
+
+	return fmt.Sprintf("%s=%s", r.name, r.arg)
+}
+
+// parseRule returns the constraint by the option of the field's tag.
+// The argument can be quoted by single quotes, like: regex='^a{1,3}$'.
+func parseRule(opt string) (r rule, err error) {
+	r.name = opt
+	if i := strings.IndexByte(opt, '='); i >= 0 {
+		r.name = strings.TrimSpace(opt[:i])
+		r.arg = strings.TrimSpace(opt[i+1:])
+		if n := len(r.arg); n >= 2 && r.arg[0] == '\'' && r.arg[n-1] == '\'' {
+			r.arg = r.arg[1 : n-1]
+		}
+	}
+
+	// The constraints with arguments.
+	switch r.name {
+	case "min", "max":
+		r.num, err = strconv.ParseFloat(r.arg, 64)
+	case "len":
+		var n uint64
+		n, err = strconv.ParseUint(r.arg, 10, 32)
+		r.num = float64(n)
+	case "oneof":
+		r.set = strings.Split(r.arg, "|")
+	case "regex":
+		r.re, err = regexp.Compile(r.arg)
+	case "nonempty", "url", "hostport", "email":
+		if len(r.arg) != 0 {
+			err = fmt.Errorf("unexpected argument")
+		}
+	default:
+		return r, fmt.Errorf("unknown option %s", opt)
+	}
+
+	if err != nil {
+		return r, fmt.Errorf("incorrect option %s: %v", opt, err)
+	}
+
+	return r, nil
+}
+
+// check returns true if the value of the field satisfies the constraint,
+// where the item is the field, the raw is the value of the variable and
+// the seq is the items of the raw value for the lists. Returns an error
+// if the constraint cannot be applied to the type of the field.
+func (r rule) check(item reflect.Value, raw string,
+	seq []string) (bool, error) {
+	switch r.name {
+	case "nonempty":
+		return len(raw) != 0, nil
+	case "min", "max", "len":
+		n, ok := measure(item, seq)
+		switch {
+		case !ok || r.name == "len" && isNumber(item):
+			return false, fmt.Errorf("%s isn't supported for %s",
+				r.name, item.Type())
+		case r.name == "min":
+			return n >= r.num, nil
+		case r.name == "max":
+			return n <= r.num, nil
+		}
+		return n == r.num, nil
+	}
+
+	// The constraints of the format are checked for each item.
+	for _, value := range seq {
+		if !r.match(value) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// match returns true if the value satisfies the constraint of the format.
+func (r rule) match(value string) bool {
+	switch r.name {
+	case "oneof":
+		for _, item := range r.set {
+			if item == value {
+				return true
+			}
+		}
+		return false
+	case "regex":
+		return r.re.MatchString(value)
+	case "url":
+		u, err := url.Parse(value)
+		return err == nil && len(u.Scheme) != 0 && len(u.Host) != 0
+	case "hostport":
+		host, port, err := net.SplitHostPort(value)
+		if err != nil || len(host) == 0 {
+			return false
+		}
+		_, err = strconv.ParseUint(port, 10, 16)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(value)
+		return err == nil && addr.Address == value
+	}
+
+	return false
+}
+
+// measure returns the value of the number field, the length of the
+// string or the number of the items of the list.
+func measure(item reflect.Value, seq []string) (float64, bool) {
+	if item.Kind() == reflect.Ptr {
+		if item.IsNil() {
+			return 0, false
+		}
+		item = item.Elem()
+	}
+
+	switch item.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16,
+		reflect.Int32, reflect.Int64:
+		return float64(item.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64:
+		return float64(item.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return item.Float(), true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(item.String())), true
+	case reflect.Array, reflect.Slice:
+		return float64(len(seq)), true
+	}
+
+	return 0, false
+}
+
+// isNumber returns true if the field is a number or pointer to number.
+func isNumber(item reflect.Value) bool {
+	t := item.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,117 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseRule tests parseRule function.
+func TestParseRule(t *testing.T) {
+	correct := map[string]string{
+		"min=1":             "min=1",
+		"max = 65535":       "max=65535",
+		"len=0":             "len=0",
+		"oneof=debug|info":  "oneof=debug|info",
+		"regex='^a{1,3}$'":  "regex=^a{1,3}$",
+		"regex=^[a-z]+$":    "regex=^[a-z]+$",
+		"nonempty":          "nonempty",
+		"url":               "url",
+		"hostport":          "hostport",
+		"email":             "email",
+		"min=-1.5":          "min=-1.5",
+		"regex='^a$' ":      "regex=^a$",
+		"max=1e3":           "max=1e3",
+		"len='8'":           "len=8",
+		"oneof='a,b|c'":     "oneof=a,b|c",
+		"regex=^\\d{2,4}$'": "regex=^\\d{2,4}$'",
+	}
+
+	for opt, expected := range correct {
+		r, err := parseRule(opt)
+		if err != nil {
+			t.Errorf("%s: %v", opt, err)
+		} else if r.String() != expected {
+			t.Errorf("%s: expected %s but returns %s", opt, expected, r)
+		}
+	}
+
+	incorrect := []string{
+		"unknown", "min", "min=a", "len=-1", "len=1.5", "regex=(",
+		"url=1", "nonempty=true",
+	}
+	for _, opt := range incorrect {
+		if _, err := parseRule(opt); err == nil {
+			t.Error("there must be a error for option:", opt)
+		}
+	}
+}
+
+// TestRuleCheck tests check method of the rule.
+func TestRuleCheck(t *testing.T) {
+	var (
+		n     = 80
+		s     = "abc"
+		list  = []string{"a", "b"}
+		tests = []struct {
+			opt   string
+			item  interface{}
+			raw   string
+			valid bool
+		}{
+			{"min=1", n, "80", true},
+			{"min=81", n, "80", false},
+			{"max=80", &n, "80", true},
+			{"max=3", s, "abc", true},
+			{"min=4", s, "abc", false},
+			{"len=3", s, "abc", true},
+			{"len=3", list, "a:b", false},
+			{"max=2", list, "a:b", true},
+			{"nonempty", s, "", false},
+			{"oneof=a|b", list, "a:b", true},
+			{"oneof=a|c", list, "a:b", false},
+			{"regex=^[a-z]+$", s, "abc", true},
+			{"regex=^[0-9]+$", s, "abc", false},
+			{"url", s, "https://example.com/path", true},
+			{"url", s, "example.com", false},
+			{"hostport", s, "localhost:8080", true},
+			{"hostport", s, "[::1]:80", true},
+			{"hostport", s, "localhost", false},
+			{"hostport", s, "localhost:http", false},
+			{"hostport", s, ":8080", false},
+			{"email", s, "user@example.com", true},
+			{"email", s, "User <user@example.com>", false},
+			{"email", s, "user", false},
+		}
+	)
+
+	for _, test := range tests {
+		r, err := parseRule(test.opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		seq := []string{test.raw}
+		if reflect.TypeOf(test.item).Kind() == reflect.Slice {
+			seq = list
+		}
+
+		valid, err := r.check(reflect.ValueOf(test.item), test.raw, seq)
+		if err != nil {
+			t.Errorf("%s for %q: %v", test.opt, test.raw, err)
+		} else if valid != test.valid {
+			t.Errorf("%s for %q: expected %t but returns %t",
+				test.opt, test.raw, test.valid, valid)
+		}
+	}
+
+	// Unsupported types.
+	r, _ := parseRule("len=2")
+	if _, err := r.check(reflect.ValueOf(n), "80", nil); err == nil {
+		t.Error("there must be a error for len of the number")
+	}
+
+	r, _ = parseRule("min=1")
+	if _, err := r.check(reflect.ValueOf(true), "true", nil); err == nil {
+		t.Error("there must be a error for min of the bool")
+	}
+}
@@ -0,0 +1,259 @@
+package env
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Write writes the entries into w in env-file format, one entry per
+// line in the given order. Only Key, Value and Action of the entries
+// are used (the directives are written as `KEY` and `unset KEY`), the
+// quoting is chosen automatically:
+//
+//    - simple value is written bare, like: KEY=value;
+//    - empty value or value with spaces, `#`, quotes, `$`, `\`, newlines
+//      and other special characters is double-quoted with escapes,
+//      like: KEY="Hello, \"\$USER\"\n".
+//
+// The multi-line values are written as heredoc blocks if the Heredoc
+// option is set, like:
+//
+//    KEY<<EOF
+//    first line
+//    second line
+//    EOF
+//
+// The written data is read back by ReadParseStore (with or without
+// expand flag) to exactly the same values.
+//
+// Returns an error if the key is incorrect, the value isn't valid UTF-8
+// or the data cannot be written.
+//
+// Examples:
+//
+//    entries := []env.Entry{
+//        {Key: "HOST", Value: "0.0.0.0"},
+//        {Key: "GREETING", Value: "Hello, $USER!"},
+//    }
+//
+//    err := env.Write(os.Stdout, entries)
+//    if err != nil {
+//        // something went wrong
+//    }
+//
+//    // Output:
+//    // HOST=0.0.0.0
+//    // GREETING="Hello, \$USER!"
+func Write(w io.Writer, entries []Entry, opts ...Option) error {
+	var (
+		bw = bufio.NewWriter(w)
+		o  = newOptions(false, false, opts)
+	)
+
+	for _, e := range entries {
+		if !o.validKey(e.Key) {
+			return fmt.Errorf("incorrect key %s", e.Key)
+		}
+
+		// The invalid UTF-8 sequences cannot be read back exactly.
+		if e.Action == Assign && !utf8.ValidString(e.Value) {
+			return fmt.Errorf("incorrect value of %s: invalid UTF-8", e.Key)
+		}
+
+		line := e.Key + "=" + quoteValue(e.Value) + "\n"
+		switch {
+		case e.Action == Inherit:
+			line = e.Key + "\n"
+		case e.Action == Remove:
+			line = "unset " + e.Key + "\n"
+		case o.heredoc != "" && strings.Contains(e.Value, "\n") &&
+			!strings.Contains(e.Value, "\r"):
+			// Choose the delimiter that isn't in the value.
+			delimiter := o.heredoc
+			for i := 1; !isHeredocValue(e.Value, delimiter); i++ {
+				delimiter = fmt.Sprintf("%s_%d", o.heredoc, i)
+			}
+
+			line = e.Key + "<<" + delimiter + "\n" + e.Value + "\n" +
+				delimiter + "\n"
+		}
+
+		if _, err := bw.WriteString(line); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// SaveFile writes the data into env-file by name, the data can be
+// map[string]string (the keys are sorted alphabetically) or []Entry
+// (the order is preserved). See Write for details of the format,
+// the opts can change the format, for example Heredoc option.
+//
+// The file is written atomically: the data is written into temporary
+// file in the same directory which is renamed to the name afterwards,
+// so the file is never partially written. The permissions of the
+// existing file are preserved, the new file is created with 0644
+// permissions.
+//
+// Examples:
+//
+//    err := env.SaveFile(".env", map[string]string{
+//        "HOST": "0.0.0.0",
+//        "PORT": "8080",
+//    })
+//    if err != nil {
+//        // something went wrong
+//    }
+func SaveFile(name string, data interface{}, opts ...Option) error {
+	var entries []Entry
+
+	switch v := data.(type) {
+	case []Entry:
+		entries = v
+	case map[string]string:
+		entries = make([]Entry, 0, len(v))
+		for key, value := range v {
+			entries = append(entries, Entry{Key: key, Value: value})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].Key < entries[j].Key
+		})
+	default:
+		return fmt.Errorf("incorrect type: %T", data)
+	}
+
+	return writeFile(name, func(w io.Writer) error {
+		return Write(w, entries, opts...)
+	})
+}
+
+// writeFile writes the data into file by name atomically: the data is
+// written by the write function into temporary file in the same
+// directory which is renamed to the name afterwards. The permissions
+// of the existing file are preserved.
+func writeFile(name string, write func(w io.Writer) error) error {
+	// Replace the target of the symbolic link, not the link itself.
+	if path, err := filepath.EvalSymlinks(name); err == nil {
+		name = path
+	}
+
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(name); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	// The temporary file must be on the same file system, the empty
+	// dir means the temporary directory for the os.CreateTemp.
+	dir, base := filepath.Split(name)
+	if dir == "" {
+		dir = "."
+	}
+
+	file, err := os.CreateTemp(dir, "."+base+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name()) // has no effect after rename
+
+	err = write(file)
+	if err == nil {
+		err = file.Sync()
+	}
+	if err == nil {
+		err = file.Chmod(perm)
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), name)
+}
+
+// isHeredocValue returns true if the value can be written as heredoc
+// block with the delimiter, i.e. the value doesn't contain a line equal
+// to the delimiter and carriage returns (they are lost while reading).
+func isHeredocValue(value, delimiter string) bool {
+	if strings.Contains(value, "\r") {
+		return false
+	}
+
+	for _, line := range strings.Split(value, "\n") {
+		if line == delimiter {
+			return false
+		}
+	}
+
+	return true
+}
+
+// quoteValue returns the value as it must be written into env-file.
+// The value is double-quoted if it contains any character that can
+// change its meaning when it is read back.
+func quoteValue(value string) string {
+	if isBareValue(value) {
+		return value
+	}
+
+	return doubleQuote(value)
+}
+
+// doubleQuote returns the double-quoted value with escaped special
+// characters.
+func doubleQuote(value string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '\\', '"', '$':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&sb, `\u%04x`, r)
+				continue
+			}
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+
+	return sb.String()
+}
+
+// isBareValue returns true if the value can be written without quotes,
+// i.e. it isn't empty and consists of letters, digits and the safe
+// punctuation characters only.
+func isBareValue(value string) bool {
+	if len(value) == 0 {
+		return false
+	}
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case strings.IndexByte("_-.,:/@+%=~^", c) >= 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
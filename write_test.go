@@ -0,0 +1,224 @@
+package env
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestQuoteValue tests quoteValue function.
+func TestQuoteValue(t *testing.T) {
+	var tests = map[string]string{
+		"value":               "value",
+		"http://host:80/path": "http://host:80/path",
+		"a=b,c+d@e%f~g^h":     "a=b,c+d@e%f~g^h",
+		"":                    `""`,
+		"two words":           `"two words"`,
+		"a#b":                 `"a#b"`,
+		`say "hi"`:            `"say \"hi\""`,
+		"it's":                `"it's"`,
+		"$HOME/${USER}":       `"\$HOME/\${USER}"`,
+		`C:\dir`:              `"C:\\dir"`,
+		"line1\nline2\r\t":    `"line1\nline2\r\t"`,
+		"bell\a":              `"bell\u0007"`,
+		"привіт":              `"привіт"`,
+	}
+
+	for value, result := range tests {
+		if v := quoteValue(value); v != result {
+			t.Errorf("For `%s` expected `%s` but returns `%s`.", value, result, v)
+		}
+	}
+}
+
+// TestWrite tests Write function.
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Write(&buf, []Entry{
+		{Key: "HOST", Value: "0.0.0.0"},
+		{Key: "GREETING", Value: "Hello, $USER!"},
+		{Key: "HOME", Value: "/root", Action: Inherit},
+		{Key: "TMP", Action: Remove},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "HOST=0.0.0.0\nGREETING=\"Hello, \\$USER!\"\n" +
+		"HOME\nunset TMP\n"
+	if v := buf.String(); v != expected {
+		t.Errorf("Expected `%s` but returns `%s`.", expected, v)
+	}
+
+	// Incorrect key.
+	if err := Write(&buf, []Entry{{Key: "1KEY", Value: "value"}}); err == nil {
+		t.Error("Expected error for incorrect key.")
+	}
+	// The value must be read back exactly: the non-ASCII characters are
+	// kept and the invalid UTF-8 is rejected.
+	buf.Reset()
+	value := "a b\u00a0\u0442\u2713\U0001F600"
+	if err := Write(&buf, []Entry{{Key: "KEY", Value: value}}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(entries) != 1 || entries[0].Value != value {
+		t.Errorf("Expected %q but returns %v.", value, entries)
+	}
+
+	buf.Reset()
+	err = Write(&buf, []Entry{{Key: "KEY", Value: "a b\xff"}})
+	if err == nil || buf.Len() != 0 {
+		t.Errorf("Expected error for invalid UTF-8 but returns %v, %q.",
+			err, buf.String())
+	}
+}
+
+// TestSaveFile tests SaveFile function, the saved data must be read
+// back to the same values.
+func TestSaveFile(t *testing.T) {
+	var (
+		name  = filepath.Join(t.TempDir(), ".env")
+		tests = map[string]string{
+			"KEY_0": "value",
+			"KEY_1": "",
+			"KEY_2": " spaces around ",
+			"KEY_3": "a # not a comment",
+			"KEY_4": `quotes ' and " and \" too`,
+			"KEY_5": "$HOME ${USER} $$ \\$ ${X:-y}",
+			"KEY_6": "multi\nline\r\nvalue\\n",
+			"KEY_7": "tab\tand\x01ctrl\x1b",
+			"KEY_8": "-----BEGIN KEY-----\nMIIEvQ==\n-----END KEY-----\n",
+			"KEY_9": "юнікод ✓",
+		}
+	)
+
+	if err := SaveFile(name, tests); err != nil {
+		t.Fatal(err)
+	}
+
+	// The map keys are sorted.
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(string(data), "KEY_0=value\nKEY_1=\"\"\n") {
+		t.Errorf("Incorrect order of the keys:\n%s", data)
+	}
+
+	for _, expand := range []bool{true, false} {
+		Clear()
+		if err := ReadParseStore(name, expand, false, false); err != nil {
+			t.Fatal(err)
+		}
+
+		for key, value := range tests {
+			if v := Get(key); v != value {
+				t.Errorf("Incorrect value for `%s` key: `%q`!=`%q`",
+					key, value, v)
+			}
+		}
+	}
+}
+
+// TestSaveFilePerm tests that SaveFile preserves the permissions
+// of the existing file.
+func TestSaveFilePerm(t *testing.T) {
+	name := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(name, []byte("KEY=old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := SaveFile(name, []Entry{{Key: "KEY", Value: "new"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Expected 0600 permissions but returns %o.", perm)
+	}
+
+	// No temporary files remain.
+	files, _ := filepath.Glob(filepath.Join(filepath.Dir(name), "*"))
+	if len(files) != 1 {
+		t.Errorf("Expected one file but returns %v.", files)
+	}
+
+	// Incorrect data type.
+	if err := SaveFile(name, []string{"KEY=value"}); err == nil {
+		t.Error("Expected error for incorrect type.")
+	}
+}
+
+// TestSaveFileRelative tests that SaveFile creates the temporary file
+// in the directory of the file with the relative name.
+func TestSaveFileRelative(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("TMPDIR", filepath.Join(dir, "missing")) // isn't used
+
+	if err := SaveFile(".env", []Entry{{Key: "KEY", Value: "value"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatal(err)
+	} else if string(data) != "KEY=value\n" {
+		t.Errorf("Expected `KEY=value\\n` but returns `%q`.", data)
+	}
+
+	// No temporary files remain.
+	files, _ := filepath.Glob(filepath.Join(dir, ".*"))
+	if len(files) != 1 {
+		t.Errorf("Expected one file but returns %v.", files)
+	}
+}
+
+// TestWriteHeredoc tests Write function with Heredoc option.
+func TestWriteHeredoc(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Write(&buf, []Entry{
+		{Key: "KEY_0", Value: "value"},
+		{Key: "KEY_1", Value: "first\nsecond $HOME\n"},
+		{Key: "KEY_2", Value: "EOF\nEOF_1"},
+		{Key: "KEY_3", Value: "carriage\r\nreturn"},
+	}, Heredoc(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "KEY_0=value\n" +
+		"KEY_1<<EOF\nfirst\nsecond $HOME\n\nEOF\n" +
+		"KEY_2<<EOF_2\nEOF\nEOF_1\nEOF_2\n" +
+		"KEY_3=\"carriage\\r\\nreturn\"\n"
+	if v := buf.String(); v != expected {
+		t.Errorf("Expected `%s` but returns `%s`.", expected, v)
+	}
+
+	// The heredoc blocks are read back to the same values.
+	entries, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, value := range []string{"value", "first\nsecond $HOME\n",
+		"EOF\nEOF_1", "carriage\r\nreturn"} {
+		if entries[i].Value != value {
+			t.Errorf("Expected `%q` but returns `%q`.", value, entries[i].Value)
+		}
+	}
+}